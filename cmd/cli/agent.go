@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/lambda/lambdachat-slackbot/internal/agent"
+	"github.com/lambda/lambdachat-slackbot/internal/agent/toolbox"
+	"github.com/lambda/lambdachat-slackbot/internal/config"
+	"github.com/lambda/lambdachat-slackbot/internal/lambdachat"
+	"github.com/spf13/cobra"
+)
+
+var agentOptions = struct {
+	name       string
+	agentsFile string
+	yolo       bool
+}{}
+
+var agentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "Chat with a configured agent that can call tools to read, modify, and run things on your behalf",
+	Run: func(cmd *cobra.Command, args []string) {
+		agents, err := config.LoadAgents(agentOptions.agentsFile)
+		if err != nil {
+			log.WithError(err).Fatal("Failed to load agents file")
+		}
+
+		var cfg *config.AgentConfig
+		for i := range agents {
+			if agents[i].Name == agentOptions.name {
+				cfg = &agents[i]
+				break
+			}
+		}
+		if cfg == nil {
+			log.Fatalf("No agent named %q in %s", agentOptions.name, agentOptions.agentsFile)
+		}
+
+		ag, err := buildAgent(*cfg, agentOptions.yolo)
+		if err != nil {
+			log.WithError(err).Fatal("Failed to build agent")
+		}
+
+		store, err := openStore()
+		if err != nil {
+			log.WithError(err).Fatal("Failed to open conversation store")
+		}
+
+		lc, err := lambdachat.New(log, rootOptions.url, rootOptions.apiKey, rootOptions.headers, rootOptions.defaultModel, lambdachat.PersonaBender, false, store, []*agent.Agent{ag}, nil, nil, rootOptions.configDir)
+		if err != nil {
+			log.WithError(err).Fatal("Failed to create Lambda Chat")
+		}
+
+		if _, err := lc.SetPersona(cliUserID, ag.Name); err != nil {
+			log.WithError(err).Fatal("Failed to bind agent")
+		}
+
+		if rootOptions.conversation != "" {
+			if err := lc.SwitchConversation(cliUserID, rootOptions.conversation); err != nil {
+				log.WithError(err).Fatalf("Failed to resume conversation %s", rootOptions.conversation)
+			}
+		}
+
+		log.Infof("Starting agent %q", ag.Name)
+		runREPL(lc)
+	},
+}
+
+// buildAgent resolves cfg's tool names into real toolbox.ToolSpecs (gating
+// modify_file and run_shell behind confirmation, per yolo) and folds any
+// attached files into the agent's system prompt as static RAG context.
+func buildAgent(cfg config.AgentConfig, yolo bool) (*agent.Agent, error) {
+	systemPrompt := cfg.SystemPrompt
+	for _, path := range cfg.Files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading attached file %s: %w", path, err)
+		}
+		systemPrompt += fmt.Sprintf("\n\n--- %s ---\n%s", filepath.Base(path), data)
+	}
+
+	tools := make([]toolbox.ToolSpec, 0, len(cfg.Tools))
+	for _, name := range cfg.Tools {
+		tool, ok := resolveTool(name, yolo)
+		if !ok {
+			return nil, fmt.Errorf("unknown tool %q", name)
+		}
+		tools = append(tools, tool)
+	}
+
+	return agent.New(cfg.Name, systemPrompt, tools...), nil
+}
+
+func init() {
+	agentCmd.Flags().StringVarP(&agentOptions.name, "agent", "a", "", "Name of the agent to chat with, as defined in --agents-file (required)")
+	agentCmd.Flags().StringVarP(&agentOptions.agentsFile, "agents-file", "", defaultAgentsFile(), "YAML file defining agents")
+	agentCmd.Flags().BoolVarP(&agentOptions.yolo, "yolo", "", false, "Run the agent's shell commands without asking for confirmation first")
+	agentCmd.MarkFlagRequired("agent")
+
+	rootCmd.AddCommand(agentCmd)
+}
+
+// defaultAgentsFile returns ~/.config/lambdachat/agents.yaml, or a relative
+// fallback if the home directory can't be determined.
+func defaultAgentsFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".config", "lambdachat", "agents.yaml")
+	}
+	return filepath.Join(home, ".config", "lambdachat", "agents.yaml")
+}
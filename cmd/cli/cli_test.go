@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/lambda/lambdachat-slackbot/internal/lambdachat"
+	"github.com/lambda/lambdachat-slackbot/internal/provider"
+	"github.com/sirupsen/logrus"
+)
+
+// testChat returns a LambdaChatter suitable for exercising command handling
+// without a real backend: its default provider is overridden by a no-op
+// fake, keyed to match DetectFromURL's guess for url so New's own
+// newDefaultProvider construction is replaced rather than run alongside it.
+func testChat(t *testing.T) lambdachat.LambdaChatter {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	const url = "http://localhost:11434"
+	fake := &noopProvider{name: provider.DetectFromURL(url)}
+
+	lc, err := lambdachat.New(logrus.NewEntry(logger), url, "test-token", nil, "", "", false, nil, nil, nil, nil, "", fake)
+	if err != nil {
+		t.Fatalf("lambdachat.New failed: %v", err)
+	}
+	return lc
+}
+
+type noopProvider struct{ name string }
+
+func (p *noopProvider) Name() string { return p.name }
+
+func (p *noopProvider) CreateChatCompletion(context.Context, provider.Request) (provider.Reply, error) {
+	return provider.Reply{Content: "ok"}, nil
+}
+
+func (p *noopProvider) CreateChatCompletionStream(_ context.Context, _ provider.Request, w io.Writer) (provider.Reply, error) {
+	_, _ = w.Write([]byte("ok"))
+	return provider.Reply{Content: "ok"}, nil
+}
+
+func TestHandleLocalCommandQuit(t *testing.T) {
+	var transcript strings.Builder
+	response, handled, quit := handleLocalCommand(nil, "user", "/quit", &transcript)
+	if !handled || !quit {
+		t.Errorf("handleLocalCommand(/quit) = handled=%v quit=%v, want both true", handled, quit)
+	}
+	if response != "" {
+		t.Errorf("handleLocalCommand(/quit) response = %q, want empty", response)
+	}
+}
+
+func TestHandleLocalCommandNotASlashCommand(t *testing.T) {
+	var transcript strings.Builder
+	_, handled, _ := handleLocalCommand(nil, "user", "just chatting", &transcript)
+	if handled {
+		t.Errorf("Expected plain text not to be handled as a local command")
+	}
+}
+
+func TestHandleLocalCommandUnknown(t *testing.T) {
+	var transcript strings.Builder
+	_, handled, _ := handleLocalCommand(nil, "user", "/not-a-real-command", &transcript)
+	if handled {
+		t.Errorf("Expected an unrecognized slash command to fall through unhandled")
+	}
+}
+
+func TestHandleLocalCommandSystemRequiresArg(t *testing.T) {
+	var transcript strings.Builder
+	response, handled, _ := handleLocalCommand(nil, "user", "/system", &transcript)
+	if !handled || !strings.Contains(response, "Usage") {
+		t.Errorf("handleLocalCommand(/system) = %q, handled=%v; want a usage message", response, handled)
+	}
+}
+
+func TestHandleLocalCommandSystemSetsPrompt(t *testing.T) {
+	lc := testChat(t)
+	var transcript strings.Builder
+	response, handled, quit := handleLocalCommand(lc, "user", "/system You are terse.", &transcript)
+	if !handled || quit {
+		t.Fatalf("handleLocalCommand(/system ...) = handled=%v quit=%v, want handled=true quit=false", handled, quit)
+	}
+	if !strings.Contains(response, "reset") {
+		t.Errorf("response = %q, want it to mention the conversation was reset", response)
+	}
+}
+
+func TestHandleLocalCommandSaveAndLoad(t *testing.T) {
+	lc := testChat(t)
+	dir := t.TempDir()
+	path := dir + "/transcript.txt"
+
+	var transcript strings.Builder
+	recordTurn(&transcript, "hello", "hi there")
+
+	response, handled, _ := handleLocalCommand(lc, "user", "/save "+path, &transcript)
+	if !handled || !strings.Contains(response, "saved") {
+		t.Fatalf("handleLocalCommand(/save) = %q, handled=%v", response, handled)
+	}
+
+	var reloaded strings.Builder
+	response, handled, _ = handleLocalCommand(lc, "user", "/load "+path, &reloaded)
+	if !handled || !strings.Contains(response, "Replayed 1 turn") {
+		t.Errorf("handleLocalCommand(/load) = %q, handled=%v, want it to report replaying 1 turn", response, handled)
+	}
+}
+
+func TestRecordTurn(t *testing.T) {
+	var transcript strings.Builder
+	recordTurn(&transcript, "hello", "hi there")
+	want := "> hello\n< hi there\n"
+	if transcript.String() != want {
+		t.Errorf("recordTurn produced %q, want %q", transcript.String(), want)
+	}
+}
+
+func TestResolveTool(t *testing.T) {
+	for _, name := range []string{"read_file", "modify_file", "list_dir", "list_directory", "dir_tree", "web_fetch", "run_shell"} {
+		if _, ok := resolveTool(name, false); !ok {
+			t.Errorf("resolveTool(%q) not found", name)
+		}
+	}
+	if _, ok := resolveTool("not_a_real_tool", false); ok {
+		t.Errorf("resolveTool of an unknown name should report false")
+	}
+}
+
+func TestResolveToolNamePreserved(t *testing.T) {
+	tool, ok := resolveTool("read_file", false)
+	if !ok || tool.Name != "read_file" {
+		t.Errorf("resolveTool(\"read_file\") = %+v, ok=%v", tool, ok)
+	}
+}
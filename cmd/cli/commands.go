@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lambda/lambdachat-slackbot/internal/lambdachat"
+)
+
+// transcriptLine prefixes distinguish a user turn from an assistant reply
+// in a saved transcript file, so /load can tell which lines to replay.
+const (
+	transcriptUserPrefix      = "> "
+	transcriptAssistantPrefix = "< "
+)
+
+// handleLocalCommand runs a CLI-only slash command that chat.HandleCommand
+// doesn't know about: /system, /save, /load, and /quit (or /exit). It
+// returns handled as false for anything else, so the caller can fall
+// through to chat.HandleCommand and then a normal chat turn. quit is true
+// only for /quit and /exit.
+func handleLocalCommand(lc lambdachat.LambdaChatter, userID, text string, transcript *strings.Builder) (response string, handled, quit bool) {
+	if !strings.HasPrefix(text, "/") {
+		return "", false, false
+	}
+
+	fields := strings.SplitN(strings.TrimPrefix(text, "/"), " ", 2)
+	name := strings.ToLower(fields[0])
+	var arg string
+	if len(fields) > 1 {
+		arg = strings.TrimSpace(fields[1])
+	}
+
+	switch name {
+	case "system":
+		if arg == "" {
+			return "Usage: /system <prompt>", true, false
+		}
+		if err := lc.SetSystemPrompt(userID, arg); err != nil {
+			return fmt.Sprintf("Error setting system prompt: %v", err), true, false
+		}
+		return "System prompt changed. Your conversation has been reset.", true, false
+
+	case "save":
+		if arg == "" {
+			return "Usage: /save <file>", true, false
+		}
+		if err := os.WriteFile(arg, []byte(transcript.String()), 0o644); err != nil {
+			return fmt.Sprintf("Error saving transcript: %v", err), true, false
+		}
+		return fmt.Sprintf("Transcript saved to %s.", arg), true, false
+
+	case "load":
+		if arg == "" {
+			return "Usage: /load <file>", true, false
+		}
+		return loadTranscript(lc, userID, arg, transcript), true, false
+
+	case "quit", "exit":
+		return "", true, true
+
+	default:
+		return "", false, false
+	}
+}
+
+// loadTranscript replays every user turn recorded in file through lc, so a
+// conversation saved with /save can be resumed in a fresh CLI session. The
+// assistant lines in the file are ignored; lc regenerates them.
+func loadTranscript(lc lambdachat.LambdaChatter, userID, file string, transcript *strings.Builder) string {
+	f, err := os.Open(file)
+	if err != nil {
+		return fmt.Sprintf("Error loading transcript: %v", err)
+	}
+	defer f.Close()
+
+	replayed := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		message, ok := strings.CutPrefix(line, transcriptUserPrefix)
+		if !ok {
+			continue
+		}
+		reply, err := lc.Chat(userID, message)
+		if err != nil {
+			return fmt.Sprintf("Error replaying %q: %v", message, err)
+		}
+		recordTurn(transcript, message, reply)
+		replayed++
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Sprintf("Error loading transcript: %v", err)
+	}
+
+	return fmt.Sprintf("Replayed %d turn(s) from %s.", replayed, file)
+}
+
+// recordTurn appends a user/assistant turn to the in-memory transcript that
+// /save writes out.
+func recordTurn(transcript *strings.Builder, userText, assistantText string) {
+	fmt.Fprintf(transcript, "%s%s\n%s%s\n", transcriptUserPrefix, userText, transcriptAssistantPrefix, assistantText)
+}
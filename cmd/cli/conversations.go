@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var conversationsCmd = &cobra.Command{
+	Use:   "conversations",
+	Short: "Inspect and manage saved conversations without starting a chat session",
+	// Overrides rootCmd's PersistentPreRun: these subcommands only touch the
+	// local conversation store, so they shouldn't need an API key or host.
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+}
+
+var conversationsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved conversations, most recently updated first",
+	Run: func(cmd *cobra.Command, args []string) {
+		store, err := openStore()
+		if err != nil {
+			log.WithError(err).Fatal("Failed to open conversation store")
+		}
+
+		convos, err := store.List(cliUserID)
+		if err != nil {
+			log.WithError(err).Fatal("Failed to list conversations")
+		}
+		if len(convos) == 0 {
+			fmt.Println("No conversations yet.")
+			return
+		}
+		for _, c := range convos {
+			fmt.Printf("%s  %s  (updated %s)\n", c.ID, c.Title, c.UpdatedAt.Format("2006-01-02 15:04"))
+		}
+	},
+}
+
+var conversationsShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Print every message in a conversation",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		store, err := openStore()
+		if err != nil {
+			log.WithError(err).Fatal("Failed to open conversation store")
+		}
+
+		messages, err := store.History(cliUserID, args[0])
+		if err != nil {
+			log.WithError(err).Fatalf("Failed to load conversation %s", args[0])
+		}
+		for _, m := range messages {
+			fmt.Printf("[%s] %s: %s\n", m.CreatedAt.Format("2006-01-02 15:04:05"), m.Role, m.Content)
+		}
+	},
+}
+
+var conversationsRmCmd = &cobra.Command{
+	Use:   "rm <id>",
+	Short: "Delete a conversation and all of its messages",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		store, err := openStore()
+		if err != nil {
+			log.WithError(err).Fatal("Failed to open conversation store")
+		}
+
+		if err := store.Delete(cliUserID, args[0]); err != nil {
+			log.WithError(err).Fatalf("Failed to delete conversation %s", args[0])
+		}
+		fmt.Printf("Deleted conversation %s.\n", args[0])
+	},
+}
+
+func init() {
+	conversationsCmd.AddCommand(conversationsListCmd, conversationsShowCmd, conversationsRmCmd)
+	rootCmd.AddCommand(conversationsCmd)
+}
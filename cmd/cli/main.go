@@ -1,12 +1,19 @@
 package main
 
 import (
-	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strings"
+	"syscall"
 
+	"github.com/chzyer/readline"
+	"github.com/lambda/lambdachat-slackbot/internal/chat"
+	"github.com/lambda/lambdachat-slackbot/internal/config"
+	"github.com/lambda/lambdachat-slackbot/internal/convstore"
 	"github.com/lambda/lambdachat-slackbot/internal/lambdachat"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -14,9 +21,24 @@ import (
 
 var version = "0.0.1~dev"
 
+// cliUserID is the fixed user ID under which the CLI stores conversations,
+// preferences, and persona/agent/model bindings.
+const cliUserID = "cli-user"
+
 var rootOptions = struct {
-	url    string
-	apiKey string
+	url          string
+	apiKey       string
+	configDir    string
+	configFile   string
+	provider     string
+	stream       bool
+	db           string
+	conversation string
+
+	// headers and defaultModel are resolved from the provider config in
+	// resolveProvider, not set directly by a flag.
+	headers      map[string]string
+	defaultModel string
 }{}
 
 var ctx, cancel = context.WithCancel(context.Background())
@@ -28,42 +50,208 @@ var rootCmd = &cobra.Command{
 	Short:   "action - command for interacting wtih Lambda Chat and Github",
 	Version: version,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if err := resolveProvider(cmd); err != nil {
+			log.WithError(err).Fatal("Failed to resolve provider configuration")
+		}
 		if rootOptions.apiKey == "" {
-			log.Fatal("API Key is required")
+			log.Fatal("API Key is required (set --api-key, LAMBDACHAT_API_KEY, or a provider's api_key in the config file)")
 		}
 		if rootOptions.url == "" {
-			log.Fatal("Host is required")
+			log.Fatal("Host is required (set --host, LAMBDACHAT_HOST, or a provider's base_url in the config file)")
 		}
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		log.Info("Starting Interactive Chat")
 
-		lc, err := lambdachat.New(log, rootOptions.url, rootOptions.apiKey, lambdachat.DefaultModel, lambdachat.PersonaBender)
+		store, err := openStore()
+		if err != nil {
+			log.WithError(err).Fatal("Failed to open conversation store")
+		}
+
+		lc, err := lambdachat.New(log, rootOptions.url, rootOptions.apiKey, rootOptions.headers, rootOptions.defaultModel, lambdachat.PersonaBender, false, store, nil, nil, nil, rootOptions.configDir)
 		if err != nil {
 			log.WithError(err).Fatal("Failed to create Lambda Chat")
 		}
 
-		// Use a fixed user ID for CLI interactions
-		const cliUserID = "cli-user"
+		if rootOptions.conversation != "" {
+			if err := lc.SwitchConversation(cliUserID, rootOptions.conversation); err != nil {
+				log.WithError(err).Fatalf("Failed to resume conversation %s", rootOptions.conversation)
+			}
+		}
+
+		// A Ctrl-C aborts whatever request is in flight (streamed or not) by
+		// cancelling lc's internal context, then exits the REPL rather than
+		// leaving it to hang on a generation that will never return.
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-sigCh
+			fmt.Println("\nInterrupted.")
+			lc.Close()
+			cancel()
+			os.Exit(0)
+		}()
+
+		runREPL(lc)
+	},
+}
+
+// configFilePath returns ~/.config/lambdachat/config.yaml, or a relative
+// fallback if the home directory can't be determined.
+func configFilePath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".config", "lambdachat", "config.yaml")
+	}
+	return filepath.Join(home, ".config", "lambdachat", "config.yaml")
+}
+
+// resolveProvider fills in rootOptions.url/apiKey/headers/defaultModel by
+// precedence: an explicitly passed flag wins, then the matching
+// LAMBDACHAT_* environment variable, then the selected provider's entry in
+// the config file. Only the host has a built-in fallback after those
+// (lambdachat.LambdaChatURL); the other settings stay empty. The provider
+// to use is --provider if set, else the config file's default_provider.
+func resolveProvider(cmd *cobra.Command) error {
+	cfg, err := config.LoadRootConfig(rootOptions.configFile)
+	if err != nil {
+		return err
+	}
+
+	name := rootOptions.provider
+	if name == "" {
+		name = cfg.DefaultProvider
+	}
+	pc := cfg.Providers[name]
+
+	if !cmd.Flags().Changed("host") {
+		switch {
+		case os.Getenv("LAMBDACHAT_HOST") != "":
+			rootOptions.url = os.Getenv("LAMBDACHAT_HOST")
+		case pc.BaseURL != "":
+			rootOptions.url = pc.BaseURL
+		default:
+			rootOptions.url = lambdachat.LambdaChatURL
+		}
+	}
+	if !cmd.Flags().Changed("api-key") {
+		if v := os.Getenv("LAMBDACHAT_API_KEY"); v != "" {
+			rootOptions.apiKey = v
+		} else if pc.APIKey != "" {
+			rootOptions.apiKey = pc.APIKey
+		}
+	}
+	rootOptions.headers = pc.Headers
+	rootOptions.defaultModel = pc.DefaultModel
+	return nil
+}
+
+// dbPath returns ~/.config/lambdachat/conversations.db, or a relative
+// fallback if the home directory can't be determined.
+func dbPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".config", "lambdachat", "conversations.db")
+	}
+	return filepath.Join(home, ".config", "lambdachat", "conversations.db")
+}
+
+// openStore opens the SQLite-backed ConversationStore at rootOptions.db,
+// creating its parent directory if necessary, so conversations survive a
+// restart instead of living only in memory for the lifetime of one process.
+func openStore() (convstore.ConversationStore, error) {
+	if err := os.MkdirAll(filepath.Dir(rootOptions.db), 0o755); err != nil {
+		return nil, fmt.Errorf("creating %s: %w", filepath.Dir(rootOptions.db), err)
+	}
+	return convstore.NewSQLite(rootOptions.db)
+}
+
+// runREPL drives the interactive readline loop shared by the plain chat
+// command and `action agent`: read a line, try the CLI-only commands, then
+// the shared chat.HandleCommand ones, and otherwise send it as a chat turn.
+func runREPL(lc lambdachat.LambdaChatter) {
+	rl, err := readline.NewEx(&readline.Config{
+		Prompt:          "Assistant query: ",
+		HistoryFile:     historyFilePath(),
+		InterruptPrompt: "^C",
+		EOFPrompt:       "/quit",
+	})
+	if err != nil {
+		log.WithError(err).Fatal("Failed to start readline")
+	}
+	defer rl.Close()
+
+	var transcript strings.Builder
+	for {
+		text, err := rl.Readline()
+		if err == readline.ErrInterrupt {
+			continue
+		}
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			log.WithError(err).Fatal("Failed to read input")
+		}
+
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
 
-		for {
-			fmt.Print("Assistant query: ")
-			reader := bufio.NewReader(os.Stdin)
-			text, _ := reader.ReadString('\n')
-			text = strings.TrimSpace(text)
+		if response, handled, quit := handleLocalCommand(lc, cliUserID, text, &transcript); handled {
+			if quit {
+				return
+			}
+			fmt.Println(response)
+			continue
+		}
 
-			out, err := lc.Chat(cliUserID, text)
-			if err != nil {
-				log.WithError(err).Fatal("Failed to chat")
+		if response, handled := chat.HandleCommand(lc, cliUserID, text); handled {
+			fmt.Println(response)
+			continue
+		}
+
+		if rootOptions.stream {
+			var reply strings.Builder
+			if err := lc.ChatStream(cliUserID, text, io.MultiWriter(os.Stdout, &reply)); err != nil {
+				log.WithError(err).Error("Failed to chat")
+				continue
 			}
-			fmt.Println(out)
+			fmt.Println()
+			recordTurn(&transcript, text, reply.String())
+			continue
 		}
-	},
+
+		out, err := lc.Chat(cliUserID, text)
+		if err != nil {
+			log.WithError(err).Error("Failed to chat")
+			continue
+		}
+		fmt.Println(out)
+		recordTurn(&transcript, text, out)
+	}
+}
+
+// historyFilePath returns ~/.lambdachat_history, or a relative fallback if
+// the home directory can't be determined.
+func historyFilePath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".lambdachat_history"
+	}
+	return filepath.Join(home, ".lambdachat_history")
 }
 
 func init() {
-	rootCmd.PersistentFlags().StringVarP(&rootOptions.apiKey, "api-key", "", "secret_vscode1_17e616d542514a3d8c73e1353da71e9b.XXJi0nsdps4gu2zDGr59I2r5HSRwFyvB", "Lambda Chat API Key")
-	rootCmd.PersistentFlags().StringVarP(&rootOptions.url, "host", "", "https://api.lambdalabs.com/v1", "Lambda Chat Host")
+	rootCmd.PersistentFlags().StringVarP(&rootOptions.apiKey, "api-key", "", "", "Lambda Chat API Key (default: $LAMBDACHAT_API_KEY, or the selected provider's api_key)")
+	rootCmd.PersistentFlags().StringVarP(&rootOptions.url, "host", "", "", "Lambda Chat Host (default: $LAMBDACHAT_HOST, or the selected provider's base_url, or "+lambdachat.LambdaChatURL+")")
+	rootCmd.PersistentFlags().StringVarP(&rootOptions.provider, "provider", "", "", "Named provider from the config file to use (default: its default_provider)")
+	rootCmd.PersistentFlags().StringVarP(&rootOptions.configFile, "config", "", configFilePath(), "YAML file defining named provider endpoints")
+	rootCmd.PersistentFlags().StringVarP(&rootOptions.configDir, "config-dir", "", "", "Directory of YAML files defining personas and models (default: bundled built-ins)")
+	rootCmd.PersistentFlags().BoolVarP(&rootOptions.stream, "stream", "", true, "Print the reply incrementally as it streams in (disable with --stream=false; there is no separate --no-stream flag)")
+	rootCmd.PersistentFlags().StringVarP(&rootOptions.db, "db", "", dbPath(), "Path to the SQLite database conversations are persisted to")
+	rootCmd.PersistentFlags().StringVarP(&rootOptions.conversation, "conversation", "", "", "Resume a specific conversation by ID instead of starting a new one")
 }
 
 func main() {
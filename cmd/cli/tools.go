@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lambda/lambdachat-slackbot/internal/agent/toolbox"
+)
+
+// shellTimeout bounds how long a single run_shell tool call may run.
+const shellTimeout = 2 * time.Minute
+
+// resolveTool looks up a built-in tool by the name used in agents.yaml.
+// modify_file and run_shell are wrapped so the CLI can preview the change
+// and ask for confirmation before it actually touches disk or runs
+// anything; yolo skips the run_shell prompt.
+func resolveTool(name string, yolo bool) (toolbox.ToolSpec, bool) {
+	switch name {
+	case "read_file":
+		return toolbox.ReadFile(), true
+	case "modify_file":
+		return confirmModifyFile(toolbox.ModifyFile()), true
+	case "list_dir", "list_directory":
+		return toolbox.ListDir(), true
+	case "dir_tree":
+		return toolbox.DirTree(), true
+	case "web_fetch":
+		return toolbox.WebFetch(), true
+	case "run_shell":
+		return confirmRunShell(toolbox.RunShell(shellTimeout), yolo), true
+	default:
+		return toolbox.ToolSpec{}, false
+	}
+}
+
+// modifyFileArgs mirrors toolbox's own (unexported) argument struct, just
+// enough to print a preview before delegating to the real implementation.
+type modifyFileArgs struct {
+	Path  string `json:"path"`
+	Patch string `json:"patch"`
+}
+
+// confirmModifyFile wraps a modify_file tool so the model's proposed patch
+// is printed and confirmed before it's applied to disk.
+func confirmModifyFile(tool toolbox.ToolSpec) toolbox.ToolSpec {
+	impl := tool.Impl
+	tool.Impl = func(ctx context.Context, args json.RawMessage) (string, error) {
+		var a modifyFileArgs
+		if err := json.Unmarshal(args, &a); err == nil {
+			fmt.Printf("\nAgent wants to patch %s:\n%s\n", a.Path, a.Patch)
+			if !confirm("Apply this patch?") {
+				return "user declined to apply this patch", nil
+			}
+		}
+		return impl(ctx, args)
+	}
+	return tool
+}
+
+// runShellArgs mirrors toolbox's own (unexported) argument struct, just
+// enough to print a preview before delegating to the real implementation.
+type runShellArgs struct {
+	Command string `json:"command"`
+}
+
+// confirmRunShell wraps a run_shell tool so the command is printed and
+// confirmed before it's executed, unless yolo is set.
+func confirmRunShell(tool toolbox.ToolSpec, yolo bool) toolbox.ToolSpec {
+	if yolo {
+		return tool
+	}
+	impl := tool.Impl
+	tool.Impl = func(ctx context.Context, args json.RawMessage) (string, error) {
+		var a runShellArgs
+		if err := json.Unmarshal(args, &a); err == nil {
+			fmt.Printf("\nAgent wants to run: %s\n", a.Command)
+			if !confirm("Run this command?") {
+				return "user declined to run this command", nil
+			}
+		}
+		return impl(ctx, args)
+	}
+	return tool
+}
+
+// confirm prompts the user with a yes/no question on stdin/stdout. Anything
+// other than a leading 'y' or 'Y' is treated as "no".
+func confirm(prompt string) bool {
+	fmt.Printf("%s [y/N] ", prompt)
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(line)), "y")
+}
@@ -2,10 +2,18 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
+	"github.com/lambda/lambdachat-slackbot/internal/agent"
+	"github.com/lambda/lambdachat-slackbot/internal/agent/toolbox"
+	"github.com/lambda/lambdachat-slackbot/internal/chat"
+	"github.com/lambda/lambdachat-slackbot/internal/chat/discord"
+	"github.com/lambda/lambdachat-slackbot/internal/chat/xmpp"
+	"github.com/lambda/lambdachat-slackbot/internal/convstore"
 	"github.com/lambda/lambdachat-slackbot/internal/lambdachat"
 	"github.com/lambda/lambdachat-slackbot/internal/slackbot"
 	"github.com/lambda/lambdachat-slackbot/internal/webui"
@@ -16,15 +24,25 @@ import (
 var version = "0.0.1~dev"
 
 var rootOptions = struct {
-	url       string
-	apiKey    string
-	appToken  string
-	botToken  string
-	model     string
-	persona   string
-	logLevel  string
-	webUI     bool
-	webUIAddr string
+	url            string
+	apiKey         string
+	appToken       string
+	botToken       string
+	model          string
+	persona        string
+	configDir      string
+	logLevel       string
+	webUI          bool
+	webUIAddr      string
+	webUIToken     string
+	editCacheSize  int
+	reactToEdits   bool
+	transport      string
+	discordToken   string
+	xmppAddress    string
+	xmppPassword   string
+	store          string
+	agentAllowlist []string
 }{}
 
 var ctx, cancel = context.WithCancel(context.Background())
@@ -51,6 +69,23 @@ var rootCmd = &cobra.Command{
 		if rootOptions.url == "" {
 			log.Fatal("Host is required")
 		}
+
+		switch rootOptions.transport {
+		case "slack":
+			if rootOptions.appToken == "" || rootOptions.botToken == "" {
+				log.Fatal("--app-token and --bot-token are required for --transport slack")
+			}
+		case "discord":
+			if rootOptions.discordToken == "" {
+				log.Fatal("--discord-token is required for --transport discord")
+			}
+		case "xmpp":
+			if rootOptions.xmppAddress == "" || rootOptions.xmppPassword == "" {
+				log.Fatal("--xmpp-address and --xmpp-password are required for --transport xmpp")
+			}
+		default:
+			log.Fatalf("Unknown --transport %q, must be slack, discord, or xmpp", rootOptions.transport)
+		}
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		log.Info("Starting Slack Bot")
@@ -66,15 +101,44 @@ var rootCmd = &cobra.Command{
 			persona = lambdachat.PersonaBender
 		}
 
-		lc, err := lambdachat.New(log, rootOptions.url, rootOptions.apiKey, model, persona)
+		// The Coder agent replaces the old qwen-25-coder AutoPersona
+		// shortcut: selecting that model now binds this agent, giving it
+		// file tools instead of just a coding-flavored system prompt.
+		coderAgent := agent.New("coder", lambdachat.PersonaCodingAssistant,
+			toolbox.ReadFile(), toolbox.ModifyFile(), toolbox.ListDir(), toolbox.WebFetch())
+
+		// Initialize WebUI if enabled. Declared before lambdachat.New so the
+		// tool-call observer closure can report to it once it exists.
+		var ui *webui.WebUI
+		toolObserver := func(userID, toolName, result string) {
+			if ui != nil {
+				ui.LogToolCall(userID, toolName, result)
+			}
+		}
+
+		store, err := openStore(rootOptions.store)
+		if err != nil {
+			log.WithError(err).Fatal("Failed to open conversation store")
+		}
+
+		lc, err := lambdachat.New(log, rootOptions.url, rootOptions.apiKey, nil, model, persona, true, store, []*agent.Agent{coderAgent}, rootOptions.agentAllowlist, toolObserver, rootOptions.configDir)
 		if err != nil {
 			log.WithError(err).Fatal("Failed to create Lambda Chat client")
 		}
 
-		// Initialize WebUI if enabled
-		var ui *webui.WebUI
 		if rootOptions.webUI {
-			ui = webui.New(log.WithField("component", "webui"))
+			ui = webui.New(log.WithField("component", "webui"), rootOptions.webUIToken)
+			ui.SetHealthProvider(func() any { return lc.HealthInfo() })
+			ui.SetChatBackend(webui.ChatBackend{
+				Chat:       lc.Chat,
+				ChatStream: lc.ChatStream,
+				ListConversations: func(userID string) (any, error) {
+					return lc.ListConversations(userID)
+				},
+				Reset:     lc.Reset,
+				ModelInfo: func() any { return lc.ModelInfo() },
+				SetModel:  lc.SetModel,
+			})
 
 			// Add logrus hook to send logs to UI
 			logHook := webui.NewLogrusHook(ui)
@@ -89,36 +153,97 @@ var rootCmd = &cobra.Command{
 			}()
 		}
 
-		// Create the Slack bot
-		bot, err := slackbot.New(log, rootOptions.appToken, rootOptions.botToken, lc, ui)
-		if err != nil {
-			log.WithError(err).Fatal("Failed to create Slack bot")
-		}
-
 		// Set up signal handling for graceful shutdown
 		sigCh := make(chan os.Signal, 1)
 		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 
-		// Start the bot in a goroutine
 		errCh := make(chan error, 1)
-		go func() {
-			errCh <- bot.Run()
-		}()
+
+		switch rootOptions.transport {
+		case "slack":
+			// Create the Slack bot
+			bot, err := slackbot.New(log, rootOptions.appToken, rootOptions.botToken, lc, ui, rootOptions.editCacheSize, rootOptions.reactToEdits, nil, nil, nil, slackbot.NewCorePlugin())
+			if err != nil {
+				log.WithError(err).Fatal("Failed to create Slack bot")
+			}
+			go func() {
+				errCh <- bot.Run()
+			}()
+
+		case "discord":
+			tp, err := discord.New(rootOptions.discordToken)
+			if err != nil {
+				log.WithError(err).Fatal("Failed to create Discord transport")
+			}
+			runTransport(tp, lc)
+			go func() {
+				errCh <- tp.Run(ctx)
+			}()
+
+		case "xmpp":
+			tp, err := xmpp.New(ctx, rootOptions.xmppAddress, rootOptions.xmppPassword)
+			if err != nil {
+				log.WithError(err).Fatal("Failed to create XMPP transport")
+			}
+			runTransport(tp, lc)
+			go func() {
+				errCh <- tp.Run(ctx)
+			}()
+		}
 
 		// Wait for either an error or a signal
 		select {
 		case err := <-errCh:
 			if err != nil {
-				log.WithError(err).Fatal("Slack bot error")
+				log.WithError(err).Fatal("Bot error")
 			}
 		case sig := <-sigCh:
 			log.Infof("Received signal %v, shutting down", sig)
 		}
 
-		log.Info("Slack bot stopped")
+		log.Info("Bot stopped")
 	},
 }
 
+// runTransport wires a chat.Transport's inbound messages to lambdaChat:
+// built-in slash commands are handled the same way across every transport
+// via chat.HandleCommand, and everything else is a normal chat turn
+// streamed back through the transport's StreamReply.
+func runTransport(tp chat.Transport, lc lambdachat.LambdaChatter) {
+	tp.OnMessage(func(ctx context.Context, msg chat.Message) {
+		if response, ok := chat.HandleCommand(lc, msg.UserID, msg.Text); ok {
+			if err := tp.Reply(ctx, msg.ConvoID, response); err != nil {
+				log.WithError(err).Error("Failed to send command reply")
+			}
+			return
+		}
+
+		w := tp.StreamReply(ctx, msg.ConvoID)
+		if err := lc.ChatStream(msg.UserID, msg.Text, w); err != nil {
+			log.WithError(err).Error("Failed to generate chat response")
+			_, _ = fmt.Fprintf(w, "Sorry, something went wrong: %v", err)
+		}
+		if err := w.Close(); err != nil {
+			log.WithError(err).Error("Failed to finalize reply")
+		}
+	})
+}
+
+// openStore builds the ConversationStore named by spec. "sqlite://path.db"
+// opens a SQLite-backed store at path.db; an empty spec returns a nil store,
+// which lambdachat.New treats as an in-memory one that doesn't survive a
+// restart.
+func openStore(spec string) (convstore.ConversationStore, error) {
+	if spec == "" {
+		return nil, nil
+	}
+	path, ok := strings.CutPrefix(spec, "sqlite://")
+	if !ok {
+		return nil, fmt.Errorf("unsupported --store scheme %q, expected sqlite://path.db", spec)
+	}
+	return convstore.NewSQLite(path)
+}
+
 func init() {
 	rootCmd.PersistentFlags().StringVarP(&rootOptions.apiKey, "api-key", "", "", "Lambda Chat API Key")
 	rootCmd.PersistentFlags().StringVarP(&rootOptions.url, "host", "", lambdachat.LambdaChatURL, "Lambda Chat Host")
@@ -126,9 +251,19 @@ func init() {
 	rootCmd.PersistentFlags().StringVarP(&rootOptions.botToken, "bot-token", "", "", "Slack Bot Token (starts with xoxb-)")
 	rootCmd.PersistentFlags().StringVarP(&rootOptions.model, "model", "", lambdachat.DefaultModel, "Lambda Chat Model (default: deepseek-llama3.3-70b)")
 	rootCmd.PersistentFlags().StringVarP(&rootOptions.persona, "persona", "", lambdachat.PersonaHelpfulAssistant, "Lambda Chat Persona (default: Bender)")
+	rootCmd.PersistentFlags().StringVarP(&rootOptions.configDir, "config-dir", "", "", "Directory of YAML files defining personas and models (default: bundled built-ins)")
 	rootCmd.PersistentFlags().StringVarP(&rootOptions.logLevel, "log-level", "", "info", "Log level (debug, info, warn, error)")
 	rootCmd.PersistentFlags().BoolVarP(&rootOptions.webUI, "webui", "", true, "Enable WebUI for logging and monitoring")
 	rootCmd.PersistentFlags().StringVarP(&rootOptions.webUIAddr, "webui-addr", "", ":8080", "WebUI server address")
+	rootCmd.PersistentFlags().StringVarP(&rootOptions.webUIToken, "webui-token", "", "", "Bearer token required to use the WebUI's chat console and admin endpoints (default: none, unsafe for non-local use)")
+	rootCmd.PersistentFlags().IntVarP(&rootOptions.editCacheSize, "edit-cache-size", "", 1000, "Number of recent messages to remember for edit/delete handling")
+	rootCmd.PersistentFlags().BoolVarP(&rootOptions.reactToEdits, "react-to-edits", "", true, "Re-answer and update the bot's reply when the triggering message is edited")
+	rootCmd.PersistentFlags().StringVarP(&rootOptions.transport, "transport", "", "slack", "Chat transport to run on: slack, discord, or xmpp")
+	rootCmd.PersistentFlags().StringVarP(&rootOptions.discordToken, "discord-token", "", "", "Discord bot token (for --transport discord)")
+	rootCmd.PersistentFlags().StringVarP(&rootOptions.xmppAddress, "xmpp-address", "", "", "XMPP JID to log in as, e.g. bot@example.net (for --transport xmpp)")
+	rootCmd.PersistentFlags().StringVarP(&rootOptions.xmppPassword, "xmpp-password", "", "", "XMPP account password (for --transport xmpp)")
+	rootCmd.PersistentFlags().StringVarP(&rootOptions.store, "store", "", "", "Conversation store, e.g. sqlite://path.db (default: in-memory, lost on restart)")
+	rootCmd.PersistentFlags().StringSliceVarP(&rootOptions.agentAllowlist, "agent-allowlist", "", nil, "Internal user IDs (e.g. slack-user-U12345) allowed to bind an agent like Coder, which runs its tools against this host's filesystem with no per-call confirmation (default: unrestricted, unsafe for a shared workspace)")
 }
 
 func main() {
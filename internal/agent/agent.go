@@ -0,0 +1,73 @@
+// Package agent generalizes a persona into a named bundle of a system
+// prompt and a set of tools the model is allowed to call.
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lambda/lambdachat-slackbot/internal/agent/toolbox"
+	"github.com/lambda/lambdachat-slackbot/internal/provider"
+)
+
+// Agent is a named bundle of a system prompt and the tools available to the
+// model while the agent is active. Tools are only exposed to the model for
+// sessions that have explicitly bound an Agent.
+type Agent struct {
+	// Name identifies the agent, e.g. for `/agent <name>` selection.
+	Name string
+
+	// SystemPrompt is injected as the conversation's system message.
+	SystemPrompt string
+
+	// Tools are the tools this agent is allowed to call.
+	Tools []toolbox.ToolSpec
+}
+
+// New creates an Agent with the given name, system prompt, and tools.
+func New(name, systemPrompt string, tools ...toolbox.ToolSpec) *Agent {
+	return &Agent{
+		Name:         name,
+		SystemPrompt: systemPrompt,
+		Tools:        tools,
+	}
+}
+
+// findTool returns the ToolSpec with the given name, if the agent allows it.
+func (a *Agent) findTool(name string) (toolbox.ToolSpec, bool) {
+	for _, t := range a.Tools {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return toolbox.ToolSpec{}, false
+}
+
+// ExecuteToolCalls runs each of the model's requested tool calls against the
+// agent's toolbox and returns the corresponding `tool` role messages to be
+// appended to the conversation.
+func (a *Agent) ExecuteToolCalls(ctx context.Context, calls []provider.ToolCall) []provider.Message {
+	results := make([]provider.Message, 0, len(calls))
+	for _, call := range calls {
+		content, err := a.executeToolCall(ctx, call)
+		if err != nil {
+			content = fmt.Sprintf("error: %v", err)
+		}
+		results = append(results, provider.Message{
+			Role:       "tool",
+			Content:    content,
+			ToolCallID: call.ID,
+			Name:       call.Name,
+		})
+	}
+	return results
+}
+
+func (a *Agent) executeToolCall(ctx context.Context, call provider.ToolCall) (string, error) {
+	tool, ok := a.findTool(call.Name)
+	if !ok {
+		return "", fmt.Errorf("tool %q is not available to agent %q", call.Name, a.Name)
+	}
+	return tool.Impl(ctx, json.RawMessage(call.Arguments))
+}
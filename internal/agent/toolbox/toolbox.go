@@ -0,0 +1,425 @@
+// Package toolbox provides the built-in tools that can be attached to an
+// agent.Agent for OpenAI-style function calling.
+package toolbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ToolSpec describes a single callable tool: its name and JSON schema as
+// presented to the model, plus the Go function that implements it.
+type ToolSpec struct {
+	// Name is the function name presented to the model.
+	Name string
+
+	// Description explains what the tool does and when to use it.
+	Description string
+
+	// Parameters is the JSON schema (as a raw object) describing the
+	// tool's arguments, following the OpenAI function-calling format.
+	Parameters json.RawMessage
+
+	// Impl executes the tool given its raw JSON arguments and returns the
+	// string result that will be sent back to the model as a tool message.
+	Impl func(ctx context.Context, args json.RawMessage) (string, error)
+}
+
+// dirTreeArgs are the arguments accepted by the dir_tree tool.
+type dirTreeArgs struct {
+	Path string `json:"path"`
+}
+
+// DirTree returns a tool that lists the contents of a directory tree.
+func DirTree() ToolSpec {
+	return ToolSpec{
+		Name:        "dir_tree",
+		Description: "List files and directories beneath a given path, recursively.",
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"path": {"type": "string", "description": "Directory to walk. Defaults to the current directory."}
+			}
+		}`),
+		Impl: func(_ context.Context, args json.RawMessage) (string, error) {
+			var a dirTreeArgs
+			if len(args) > 0 {
+				if err := json.Unmarshal(args, &a); err != nil {
+					return "", fmt.Errorf("dir_tree: invalid arguments: %w", err)
+				}
+			}
+			root := a.Path
+			if root == "" {
+				root = "."
+			}
+
+			var sb strings.Builder
+			err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+				if err != nil {
+					return err
+				}
+				rel, relErr := filepath.Rel(root, path)
+				if relErr != nil {
+					rel = path
+				}
+				if rel == "." {
+					return nil
+				}
+				if info.IsDir() {
+					fmt.Fprintf(&sb, "%s/\n", rel)
+				} else {
+					fmt.Fprintf(&sb, "%s\n", rel)
+				}
+				return nil
+			})
+			if err != nil {
+				return "", fmt.Errorf("dir_tree: %w", err)
+			}
+			return sb.String(), nil
+		},
+	}
+}
+
+// listDirArgs are the arguments accepted by the list_dir tool.
+type listDirArgs struct {
+	Path string `json:"path"`
+}
+
+// ListDir returns a tool that lists the immediate contents of a directory,
+// one level deep. Prefer DirTree when a recursive listing is useful.
+func ListDir() ToolSpec {
+	return ToolSpec{
+		Name:        "list_dir",
+		Description: "List the immediate files and directories within a given path, non-recursively.",
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"path": {"type": "string", "description": "Directory to list. Defaults to the current directory."}
+			}
+		}`),
+		Impl: func(_ context.Context, args json.RawMessage) (string, error) {
+			var a listDirArgs
+			if len(args) > 0 {
+				if err := json.Unmarshal(args, &a); err != nil {
+					return "", fmt.Errorf("list_dir: invalid arguments: %w", err)
+				}
+			}
+			root := a.Path
+			if root == "" {
+				root = "."
+			}
+
+			entries, err := os.ReadDir(root)
+			if err != nil {
+				return "", fmt.Errorf("list_dir: %w", err)
+			}
+
+			var sb strings.Builder
+			for _, entry := range entries {
+				if entry.IsDir() {
+					fmt.Fprintf(&sb, "%s/\n", entry.Name())
+				} else {
+					fmt.Fprintf(&sb, "%s\n", entry.Name())
+				}
+			}
+			return sb.String(), nil
+		},
+	}
+}
+
+// readFileArgs are the arguments accepted by the read_file tool.
+type readFileArgs struct {
+	Path string `json:"path"`
+}
+
+// ReadFile returns a tool that reads a file's contents from disk.
+func ReadFile() ToolSpec {
+	return ToolSpec{
+		Name:        "read_file",
+		Description: "Read and return the contents of a file on disk.",
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"path": {"type": "string", "description": "Path of the file to read."}
+			},
+			"required": ["path"]
+		}`),
+		Impl: func(_ context.Context, args json.RawMessage) (string, error) {
+			var a readFileArgs
+			if err := json.Unmarshal(args, &a); err != nil {
+				return "", fmt.Errorf("read_file: invalid arguments: %w", err)
+			}
+			if a.Path == "" {
+				return "", fmt.Errorf("read_file: path is required")
+			}
+			data, err := os.ReadFile(a.Path)
+			if err != nil {
+				return "", fmt.Errorf("read_file: %w", err)
+			}
+			return string(data), nil
+		},
+	}
+}
+
+// modifyFileArgs are the arguments accepted by the modify_file tool.
+type modifyFileArgs struct {
+	Path  string `json:"path"`
+	Patch string `json:"patch"`
+}
+
+// ModifyFile returns a tool that applies a unified-diff-style patch (as
+// produced by `diff -u`, with `@@ -l,s +l,s @@` hunk headers and ` `/`-`/`+`
+// prefixed lines) to a single file on disk.
+func ModifyFile() ToolSpec {
+	return ToolSpec{
+		Name:        "modify_file",
+		Description: "Apply a unified-diff patch (context/-/+ prefixed lines under `@@` hunk headers) to a file on disk.",
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"path": {"type": "string", "description": "Path of the file to modify."},
+				"patch": {"type": "string", "description": "Unified-diff hunks to apply, without the file header lines (---/+++)."}
+			},
+			"required": ["path", "patch"]
+		}`),
+		Impl: func(_ context.Context, args json.RawMessage) (string, error) {
+			var a modifyFileArgs
+			if err := json.Unmarshal(args, &a); err != nil {
+				return "", fmt.Errorf("modify_file: invalid arguments: %w", err)
+			}
+			if a.Path == "" {
+				return "", fmt.Errorf("modify_file: path is required")
+			}
+
+			original, err := os.ReadFile(a.Path)
+			if err != nil {
+				return "", fmt.Errorf("modify_file: %w", err)
+			}
+
+			patched, err := applyUnifiedDiff(string(original), a.Patch)
+			if err != nil {
+				return "", fmt.Errorf("modify_file: %w", err)
+			}
+
+			if err := os.WriteFile(a.Path, []byte(patched), 0o644); err != nil {
+				return "", fmt.Errorf("modify_file: %w", err)
+			}
+			return fmt.Sprintf("patched %s", a.Path), nil
+		},
+	}
+}
+
+// applyUnifiedDiff applies patch's hunks to original's lines and returns the
+// result. Each hunk's context (" ") and removed ("-") lines are matched
+// against original starting at the hunk's declared line number; added ("+")
+// lines are inserted in their place.
+func applyUnifiedDiff(original, patch string) (string, error) {
+	srcLines := splitLines(original)
+	var out []string
+	srcPos := 0 // next unconsumed index into srcLines
+
+	scanner := bufio.NewScanner(strings.NewReader(patch))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "@@") {
+			continue
+		}
+
+		hunkStart, err := parseHunkOldStart(line)
+		if err != nil {
+			return "", err
+		}
+		// Copy any untouched lines before this hunk.
+		for srcPos < hunkStart-1 {
+			out = append(out, srcLines[srcPos])
+			srcPos++
+		}
+
+		for scanner.Scan() {
+			hl := scanner.Text()
+			if strings.HasPrefix(hl, "@@") {
+				// Next hunk header; re-process it on the outer loop.
+				hunkStart, err = parseHunkOldStart(hl)
+				if err != nil {
+					return "", err
+				}
+				for srcPos < hunkStart-1 {
+					out = append(out, srcLines[srcPos])
+					srcPos++
+				}
+				continue
+			}
+			if hl == "" {
+				break
+			}
+			switch hl[0] {
+			case ' ':
+				if srcPos >= len(srcLines) || srcLines[srcPos] != hl[1:] {
+					return "", fmt.Errorf("context line %q does not match %q at line %d", hl[1:], srcLineAt(srcLines, srcPos), srcPos+1)
+				}
+				out = append(out, srcLines[srcPos])
+				srcPos++
+			case '-':
+				if srcPos >= len(srcLines) || srcLines[srcPos] != hl[1:] {
+					return "", fmt.Errorf("removed line %q does not match %q at line %d", hl[1:], srcLineAt(srcLines, srcPos), srcPos+1)
+				}
+				srcPos++
+			case '+':
+				out = append(out, hl[1:])
+			default:
+				return "", fmt.Errorf("unrecognized patch line: %q", hl)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("reading patch: %w", err)
+	}
+
+	out = append(out, srcLines[srcPos:]...)
+	return strings.Join(out, "\n"), nil
+}
+
+// parseHunkOldStart extracts the starting line number of the "old file" side
+// of a "@@ -l,s +l,s @@" hunk header.
+func parseHunkOldStart(header string) (int, error) {
+	fields := strings.Fields(header)
+	if len(fields) < 2 || !strings.HasPrefix(fields[1], "-") {
+		return 0, fmt.Errorf("malformed hunk header: %q", header)
+	}
+	oldRange := strings.TrimPrefix(fields[1], "-")
+	lineStr := strings.SplitN(oldRange, ",", 2)[0]
+	line, err := strconv.Atoi(lineStr)
+	if err != nil {
+		return 0, fmt.Errorf("malformed hunk header: %q", header)
+	}
+	return line, nil
+}
+
+// splitLines splits s on "\n" without discarding a trailing empty element,
+// so re-joining with "\n" round-trips a file that doesn't end in a newline.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// srcLineAt returns lines[i], or "<eof>" if i is out of range, for error messages.
+func srcLineAt(lines []string, i int) string {
+	if i < 0 || i >= len(lines) {
+		return "<eof>"
+	}
+	return lines[i]
+}
+
+// webFetchArgs are the arguments accepted by the web_fetch tool.
+type webFetchArgs struct {
+	URL string `json:"url"`
+}
+
+// WebFetch returns a tool that fetches a URL over HTTP and returns the body.
+func WebFetch() ToolSpec {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	return ToolSpec{
+		Name:        "web_fetch",
+		Description: "Fetch the contents of a URL over HTTP(S) and return the response body.",
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"url": {"type": "string", "description": "URL to fetch."}
+			},
+			"required": ["url"]
+		}`),
+		Impl: func(ctx context.Context, args json.RawMessage) (string, error) {
+			var a webFetchArgs
+			if err := json.Unmarshal(args, &a); err != nil {
+				return "", fmt.Errorf("web_fetch: invalid arguments: %w", err)
+			}
+			if a.URL == "" {
+				return "", fmt.Errorf("web_fetch: url is required")
+			}
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.URL, nil)
+			if err != nil {
+				return "", fmt.Errorf("web_fetch: %w", err)
+			}
+
+			resp, err := client.Do(req)
+			if err != nil {
+				return "", fmt.Errorf("web_fetch: %w", err)
+			}
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+			if err != nil {
+				return "", fmt.Errorf("web_fetch: %w", err)
+			}
+			return string(body), nil
+		},
+	}
+}
+
+// runShellArgs are the arguments accepted by the run_shell tool.
+type runShellArgs struct {
+	Command string `json:"command"`
+}
+
+// RunShell returns a tool that runs a shell command via "sh -c" and returns
+// its combined output, bounded only by timeout: the command runs as the
+// calling process, with its full filesystem/network/credentials, there is no
+// container/restricted-user/seccomp sandbox around it. It is not part of
+// Builtins: running arbitrary shell commands is dangerous enough that an
+// agent must opt in to it explicitly, and callers should gate autoRunTools
+// for it behind a human confirmation (as the CLI's confirmRunShell does)
+// rather than trusting the timeout alone.
+func RunShell(timeout time.Duration) ToolSpec {
+	return ToolSpec{
+		Name:        "run_shell",
+		Description: "Run a shell command and return its combined stdout/stderr.",
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"command": {"type": "string", "description": "Command to run via \"sh -c\"."}
+			},
+			"required": ["command"]
+		}`),
+		Impl: func(ctx context.Context, args json.RawMessage) (string, error) {
+			var a runShellArgs
+			if err := json.Unmarshal(args, &a); err != nil {
+				return "", fmt.Errorf("run_shell: invalid arguments: %w", err)
+			}
+			if a.Command == "" {
+				return "", fmt.Errorf("run_shell: command is required")
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			cmd := exec.CommandContext(ctx, "sh", "-c", a.Command)
+			output, err := cmd.CombinedOutput()
+			output = output[:min(len(output), 1<<20)]
+			if err != nil {
+				return string(output), fmt.Errorf("run_shell: %w", err)
+			}
+			return string(output), nil
+		},
+	}
+}
+
+// Builtins returns the toolbox's default tool set, excluding run_shell,
+// which an agent must opt into.
+func Builtins() []ToolSpec {
+	return []ToolSpec{DirTree(), ListDir(), ReadFile(), ModifyFile(), WebFetch()}
+}
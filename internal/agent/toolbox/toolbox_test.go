@@ -0,0 +1,170 @@
+package toolbox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func callTool(t *testing.T, tool ToolSpec, args any) (string, error) {
+	t.Helper()
+	var raw json.RawMessage
+	if args != nil {
+		encoded, err := json.Marshal(args)
+		if err != nil {
+			t.Fatalf("marshaling args: %v", err)
+		}
+		raw = encoded
+	}
+	return tool.Impl(context.Background(), raw)
+}
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hello.txt")
+	if err := os.WriteFile(path, []byte("hello world"), 0o644); err != nil {
+		t.Fatalf("writing fixture: %v", err)
+	}
+
+	out, err := callTool(t, ReadFile(), map[string]string{"path": path})
+	if err != nil {
+		t.Fatalf("read_file failed: %v", err)
+	}
+	if out != "hello world" {
+		t.Errorf("read_file = %q, want %q", out, "hello world")
+	}
+}
+
+func TestReadFileRequiresPath(t *testing.T) {
+	if _, err := callTool(t, ReadFile(), map[string]string{"path": ""}); err == nil {
+		t.Fatalf("Expected an error for an empty path")
+	}
+}
+
+func TestListDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), nil, 0o644); err != nil {
+		t.Fatalf("writing fixture: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatalf("creating fixture dir: %v", err)
+	}
+
+	out, err := callTool(t, ListDir(), map[string]string{"path": dir})
+	if err != nil {
+		t.Fatalf("list_dir failed: %v", err)
+	}
+	if !strings.Contains(out, "a.txt") || !strings.Contains(out, "sub/") {
+		t.Errorf("list_dir = %q, want it to list a.txt and sub/", out)
+	}
+}
+
+func TestDirTree(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatalf("creating fixture dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sub", "nested.txt"), nil, 0o644); err != nil {
+		t.Fatalf("writing fixture: %v", err)
+	}
+
+	out, err := callTool(t, DirTree(), map[string]string{"path": dir})
+	if err != nil {
+		t.Fatalf("dir_tree failed: %v", err)
+	}
+	if !strings.Contains(out, filepath.Join("sub", "nested.txt")) {
+		t.Errorf("dir_tree = %q, want it to include the nested file", out)
+	}
+}
+
+func TestModifyFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "code.go")
+	original := "line one\nline two\nline three"
+	if err := os.WriteFile(path, []byte(original), 0o644); err != nil {
+		t.Fatalf("writing fixture: %v", err)
+	}
+
+	patch := "@@ -1,3 +1,3 @@\n line one\n-line two\n+line TWO\n line three"
+	out, err := callTool(t, ModifyFile(), map[string]string{"path": path, "patch": patch})
+	if err != nil {
+		t.Fatalf("modify_file failed: %v", err)
+	}
+	if !strings.Contains(out, "patched") {
+		t.Errorf("modify_file result = %q, want it to confirm the patch", out)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading patched file: %v", err)
+	}
+	want := "line one\nline TWO\nline three"
+	if string(got) != want {
+		t.Errorf("patched file = %q, want %q", got, want)
+	}
+}
+
+func TestModifyFileRejectsMismatchedContext(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "code.go")
+	if err := os.WriteFile(path, []byte("line one\nline two"), 0o644); err != nil {
+		t.Fatalf("writing fixture: %v", err)
+	}
+
+	patch := "@@ -1,2 +1,2 @@\n line one\n-line NOT THERE\n+line two edited"
+	if _, err := callTool(t, ModifyFile(), map[string]string{"path": path, "patch": patch}); err == nil {
+		t.Fatalf("Expected an error when the patch's context doesn't match the file")
+	}
+}
+
+func TestWebFetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("fetched body"))
+	}))
+	defer srv.Close()
+
+	out, err := callTool(t, WebFetch(), map[string]string{"url": srv.URL})
+	if err != nil {
+		t.Fatalf("web_fetch failed: %v", err)
+	}
+	if out != "fetched body" {
+		t.Errorf("web_fetch = %q, want %q", out, "fetched body")
+	}
+}
+
+func TestWebFetchRequiresURL(t *testing.T) {
+	if _, err := callTool(t, WebFetch(), map[string]string{"url": ""}); err == nil {
+		t.Fatalf("Expected an error for an empty URL")
+	}
+}
+
+func TestRunShell(t *testing.T) {
+	out, err := callTool(t, RunShell(5*time.Second), map[string]string{"command": "echo -n hello"})
+	if err != nil {
+		t.Fatalf("run_shell failed: %v", err)
+	}
+	if out != "hello" {
+		t.Errorf("run_shell = %q, want %q", out, "hello")
+	}
+}
+
+func TestRunShellTimesOut(t *testing.T) {
+	_, err := callTool(t, RunShell(10*time.Millisecond), map[string]string{"command": "sleep 1"})
+	if err == nil {
+		t.Fatalf("Expected run_shell to error when the command exceeds its timeout")
+	}
+}
+
+func TestBuiltinsExcludesRunShell(t *testing.T) {
+	for _, tool := range Builtins() {
+		if tool.Name == "run_shell" {
+			t.Errorf("Builtins() must not include run_shell, which an agent has to opt into explicitly")
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package chat
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lambda/lambdachat-slackbot/internal/lambdachat"
+)
+
+// HandleCommand runs one of lambdachat's built-in slash commands (reset,
+// persona(s), agent, model(s), health, conversations, resume, rename,
+// delete) on behalf of userID if text is one (i.e. starts with "/"),
+// returning its response text and true. It returns false
+// if text isn't a recognized command, so the caller can fall through to a
+// normal chat turn. This gives every Transport the same built-in commands
+// slackbot's corePlugin offers Slack, without depending on that package.
+func HandleCommand(lc lambdachat.LambdaChatter, userID, text string) (string, bool) {
+	if !strings.HasPrefix(text, "/") {
+		return "", false
+	}
+
+	fields := strings.SplitN(strings.TrimPrefix(text, "/"), " ", 2)
+	name := strings.ToLower(fields[0])
+	var arg string
+	if len(fields) > 1 {
+		arg = strings.TrimSpace(fields[1])
+	}
+
+	switch name {
+	case "reset":
+		if err := lc.Reset(userID); err != nil {
+			return fmt.Sprintf("Error resetting conversation: %v", err), true
+		}
+		return "Conversation has been reset.", true
+
+	case "persona":
+		if arg == "" {
+			return listPersonas(lc), true
+		}
+		response, err := lc.SetPersona(userID, arg)
+		return setOrError(response, err, "setting persona"), true
+
+	case "personas":
+		return listPersonas(lc), true
+
+	case "agent":
+		if arg == "" {
+			return listAgents(lc), true
+		}
+		response, err := lc.SetPersona(userID, arg)
+		return setOrError(response, err, "setting agent"), true
+
+	case "model":
+		if arg == "" {
+			return listModels(lc), true
+		}
+		response, err := lc.SetModel(userID, arg)
+		return setOrError(response, err, "setting model"), true
+
+	case "models":
+		return listModels(lc), true
+
+	case "health":
+		return formatHealth(lc), true
+
+	case "conversations":
+		return listConversations(lc, userID), true
+
+	case "resume":
+		if arg == "" {
+			return "Usage: /resume <id>", true
+		}
+		if err := lc.SwitchConversation(userID, arg); err != nil {
+			return fmt.Sprintf("Error resuming conversation: %v", err), true
+		}
+		return fmt.Sprintf("Resumed conversation %s.", arg), true
+
+	case "rename":
+		id, newTitle, ok := splitTwo(arg)
+		if !ok {
+			return "Usage: /rename <id> <title>", true
+		}
+		if err := lc.RenameConversation(userID, id, newTitle); err != nil {
+			return fmt.Sprintf("Error renaming conversation: %v", err), true
+		}
+		return fmt.Sprintf("Renamed conversation %s to %q.", id, newTitle), true
+
+	case "delete":
+		if arg == "" {
+			return "Usage: /delete <id>", true
+		}
+		if err := lc.DeleteConversation(userID, arg); err != nil {
+			return fmt.Sprintf("Error deleting conversation: %v", err), true
+		}
+		return fmt.Sprintf("Deleted conversation %s.", arg), true
+
+	default:
+		return "", false
+	}
+}
+
+// splitTwo splits arg into its first whitespace-delimited field and the
+// remainder, used by /rename's "<id> <title>" syntax. ok is false if arg
+// doesn't contain both.
+func splitTwo(arg string) (first, rest string, ok bool) {
+	fields := strings.SplitN(arg, " ", 2)
+	if len(fields) != 2 || strings.TrimSpace(fields[1]) == "" {
+		return "", "", false
+	}
+	return fields[0], strings.TrimSpace(fields[1]), true
+}
+
+func setOrError(response string, err error, action string) string {
+	if err != nil {
+		return fmt.Sprintf("Error %s: %v", action, err)
+	}
+	return response
+}
+
+func listPersonas(lc lambdachat.LambdaChatter) string {
+	var sb strings.Builder
+	sb.WriteString("Available personas:\n")
+	for _, p := range lc.PersonaInfo() {
+		fmt.Fprintf(&sb, "- %s: %s\n", p.Name, p.Description)
+	}
+	return sb.String()
+}
+
+func listAgents(lc lambdachat.LambdaChatter) string {
+	var sb strings.Builder
+	sb.WriteString("Available agents:\n")
+	for _, a := range lc.AgentInfo() {
+		fmt.Fprintf(&sb, "- %s (tools: %s)\n", a.Name, strings.Join(a.ToolNames, ", "))
+	}
+	return sb.String()
+}
+
+func listModels(lc lambdachat.LambdaChatter) string {
+	var sb strings.Builder
+	sb.WriteString("Available models:\n")
+	for _, m := range lc.ModelInfo() {
+		fmt.Fprintf(&sb, "- %s: %s\n", m.Name, m.Description)
+	}
+	return sb.String()
+}
+
+// listConversations renders a user's conversations, most recently updated
+// first, for the `/conversations` command.
+func listConversations(lc lambdachat.LambdaChatter, userID string) string {
+	convos, err := lc.ListConversations(userID)
+	if err != nil {
+		return fmt.Sprintf("Error listing conversations: %v", err)
+	}
+	if len(convos) == 0 {
+		return "You have no conversations yet."
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Your conversations:\n")
+	for _, c := range convos {
+		fmt.Fprintf(&sb, "- %s: %s (updated %s)\n", c.ID, c.Title, c.UpdatedAt.Format("2006-01-02 15:04"))
+	}
+	return sb.String()
+}
+
+// formatHealth renders a table of model -> status/cooldown/last-error for
+// the `/health` command.
+func formatHealth(lc lambdachat.LambdaChatter) string {
+	var sb strings.Builder
+	sb.WriteString("Model health:\n")
+	for _, h := range lc.HealthInfo() {
+		status := "healthy"
+		if !h.Healthy {
+			status = fmt.Sprintf("cooling down until %s", h.CooldownUntil.Format("15:04:05"))
+		}
+		fmt.Fprintf(&sb, "- %s: %s", h.Model, status)
+		if h.LastError != "" {
+			fmt.Fprintf(&sb, " (last error: %s)", h.LastError)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,149 @@
+// Package discord implements chat.Transport on top of discordgo, so the
+// lambdaChat core can run as a Discord bot.
+package discord
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/lambda/lambdachat-slackbot/internal/chat"
+)
+
+// streamUpdateInterval bounds how often streamWriter calls
+// ChannelMessageEdit while a reply is streaming in, mirroring the Slack
+// transport's streamUpdateInterval; Discord's message-edit rate limit is
+// tighter than Slack's, so editing on every token would start failing with
+// 429s on any non-trivial reply.
+const streamUpdateInterval = 750 * time.Millisecond
+
+// Transport drives a Discord bot connection via discordgo.
+type Transport struct {
+	session *discordgo.Session
+	handler func(ctx context.Context, msg chat.Message)
+}
+
+// New creates a Transport authenticated with a bot token (without the "Bot "
+// prefix; New adds it).
+func New(token string) (*Transport, error) {
+	session, err := discordgo.New("Bot " + token)
+	if err != nil {
+		return nil, fmt.Errorf("discord: %w", err)
+	}
+	// We only need to read message content in guilds and DMs and post
+	// replies; request the narrowest intents that allows.
+	session.Identify.Intents = discordgo.IntentGuilds |
+		discordgo.IntentGuildMessages |
+		discordgo.IntentDirectMessages |
+		discordgo.IntentMessageContent
+
+	return &Transport{session: session}, nil
+}
+
+// UserID translates a Discord user snowflake into lambdachat's userID key.
+func UserID(discordUserID string) string {
+	return fmt.Sprintf("discord-user-%s", discordUserID)
+}
+
+// OnMessage implements chat.Transport.
+func (t *Transport) OnMessage(handler func(ctx context.Context, msg chat.Message)) {
+	t.handler = handler
+}
+
+// Run implements chat.Transport.
+func (t *Transport) Run(ctx context.Context) error {
+	t.session.AddHandler(func(_ *discordgo.Session, m *discordgo.MessageCreate) {
+		if t.handler == nil || m.Author == nil || m.Author.Bot || m.Content == "" {
+			return
+		}
+		t.handler(ctx, chat.Message{
+			UserID:  UserID(m.Author.ID),
+			ConvoID: m.ChannelID,
+			Text:    m.Content,
+		})
+	})
+
+	if err := t.session.Open(); err != nil {
+		return fmt.Errorf("discord: opening gateway connection: %w", err)
+	}
+	defer t.session.Close()
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// Reply implements chat.Transport.
+func (t *Transport) Reply(ctx context.Context, convoID, text string) error {
+	_, err := t.session.ChannelMessageSend(convoID, text, discordgo.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("discord: sending message: %w", err)
+	}
+	return nil
+}
+
+// StreamReply implements chat.Transport. It posts an initial message on the
+// first Write and edits it in place on each subsequent one, mirroring the
+// Slack transport's incremental chat.update behavior.
+func (t *Transport) StreamReply(ctx context.Context, convoID string) io.WriteCloser {
+	return &streamWriter{ctx: ctx, session: t.session, channelID: convoID}
+}
+
+// Presence implements chat.Transport. discordgo only exposes presence for
+// members of a guild whose state is cached locally, which a userID alone
+// isn't enough to look up, so this always returns "".
+func (t *Transport) Presence(string) string {
+	return ""
+}
+
+// streamWriter accumulates written content into a single Discord message,
+// posting it on the first Write and editing it in place thereafter. Edits
+// are rate-limited to streamUpdateInterval, same as Slack's
+// threadMessageWriter, instead of firing on every chunk.
+type streamWriter struct {
+	ctx       context.Context
+	session   *discordgo.Session
+	channelID string
+	messageID string
+	content   strings.Builder
+	lastEdit  time.Time
+}
+
+func (w *streamWriter) Write(p []byte) (int, error) {
+	w.content.Write(p)
+
+	if w.messageID == "" {
+		msg, err := w.session.ChannelMessageSend(w.channelID, w.content.String(), discordgo.WithContext(w.ctx))
+		if err != nil {
+			return 0, fmt.Errorf("discord: posting initial reply: %w", err)
+		}
+		w.messageID = msg.ID
+		w.lastEdit = time.Now()
+		return len(p), nil
+	}
+
+	if time.Since(w.lastEdit) < streamUpdateInterval {
+		return len(p), nil
+	}
+
+	if _, err := w.session.ChannelMessageEdit(w.channelID, w.messageID, w.content.String(), discordgo.WithContext(w.ctx)); err != nil {
+		return 0, fmt.Errorf("discord: updating reply: %w", err)
+	}
+	w.lastEdit = time.Now()
+	return len(p), nil
+}
+
+// Close implements io.WriteCloser, flushing whatever content was withheld by
+// the last Write's rate limit so the message ends up fully up to date.
+func (w *streamWriter) Close() error {
+	if w.messageID == "" {
+		return nil
+	}
+	if _, err := w.session.ChannelMessageEdit(w.channelID, w.messageID, w.content.String(), discordgo.WithContext(w.ctx)); err != nil {
+		return fmt.Errorf("discord: flushing final reply: %w", err)
+	}
+	return nil
+}
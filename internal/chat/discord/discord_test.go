@@ -0,0 +1,9 @@
+package discord
+
+import "testing"
+
+func TestUserID(t *testing.T) {
+	if got, want := UserID("12345"), "discord-user-12345"; got != want {
+		t.Errorf("UserID(%q) = %q, want %q", "12345", got, want)
+	}
+}
@@ -0,0 +1,51 @@
+// Package chat abstracts a chat platform (Slack, Discord, XMPP, ...) behind
+// a small Transport interface, so the same lambdaChat core can be driven by
+// whichever one cmd/slackbot is configured to run against.
+package chat
+
+import (
+	"context"
+	"io"
+)
+
+// Message is one inbound message, translated from a transport's native event
+// into the shape OnMessage handlers consume.
+type Message struct {
+	// UserID is the sender's identity translated into the namespaced form
+	// lambdachat's userConvo/userPersonas/userModels maps key on, e.g.
+	// "slack-user-U123", "discord-user-456", "xmpp-user-juliet@example.com".
+	UserID string
+
+	// ConvoID identifies where Reply/StreamReply should send a response: a
+	// Slack channel ID, a Discord channel ID, or an XMPP JID.
+	ConvoID string
+
+	// Text is the message body.
+	Text string
+}
+
+// Transport drives one chat platform's connection and message loop,
+// translating its native identities into lambdachat's userID key, so the
+// same lambdaChat core can run unmodified on Slack, Discord, XMPP, or
+// anything else that implements this interface.
+type Transport interface {
+	// Run connects to the platform and blocks, dispatching inbound messages
+	// to the handler registered via OnMessage, until ctx is canceled or an
+	// unrecoverable error occurs.
+	Run(ctx context.Context) error
+
+	// OnMessage registers the handler invoked for every inbound message. A
+	// later call replaces the prior handler; only one is supported.
+	OnMessage(handler func(ctx context.Context, msg Message))
+
+	// Reply sends a complete message back to convoID.
+	Reply(ctx context.Context, convoID, text string) error
+
+	// StreamReply returns a writer that incrementally posts to convoID as
+	// content is written to it, finalizing the reply when Close is called.
+	StreamReply(ctx context.Context, convoID string) io.WriteCloser
+
+	// Presence reports a short, human-readable status for userID (e.g.
+	// "online", "idle"), or "" if the transport doesn't expose one.
+	Presence(userID string) string
+}
@@ -0,0 +1,152 @@
+// Package xmpp implements chat.Transport on top of mellium.im/xmpp, so the
+// lambdaChat core can run as an XMPP (Jabber) bot.
+package xmpp
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"mellium.im/sasl"
+	"mellium.im/xmlstream"
+	"mellium.im/xmpp"
+	"mellium.im/xmpp/jid"
+	"mellium.im/xmpp/stanza"
+
+	"github.com/lambda/lambdachat-slackbot/internal/chat"
+)
+
+// Transport drives an XMPP client-to-server session via mellium.im/xmpp.
+type Transport struct {
+	session *xmpp.Session
+	handler func(ctx context.Context, msg chat.Message)
+}
+
+// New dials and authenticates an XMPP session as address (e.g.
+// "bot@example.net"), using password for SASL auth.
+func New(ctx context.Context, address, password string) (*Transport, error) {
+	j, err := jid.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("xmpp: invalid address %q: %w", address, err)
+	}
+
+	session, err := xmpp.DialClientSession(
+		ctx, j,
+		xmpp.BindResource(),
+		xmpp.StartTLS(&tls.Config{ServerName: j.Domain().String()}),
+		xmpp.SASL("", password, sasl.ScramSha1Plus, sasl.ScramSha1, sasl.Plain),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("xmpp: establishing session: %w", err)
+	}
+
+	return &Transport{session: session}, nil
+}
+
+// UserID translates a bare XMPP JID into lambdachat's userID key.
+func UserID(from jid.JID) string {
+	return fmt.Sprintf("xmpp-user-%s", from.Bare())
+}
+
+// messageBody is a chat message stanza together with its plain-text body,
+// the same shape mellium's own echobot example decodes into.
+type messageBody struct {
+	stanza.Message
+	Body string `xml:"body"`
+}
+
+// OnMessage implements chat.Transport.
+func (t *Transport) OnMessage(handler func(ctx context.Context, msg chat.Message)) {
+	t.handler = handler
+}
+
+// Run implements chat.Transport.
+func (t *Transport) Run(ctx context.Context) error {
+	// Announce availability so the server starts routing messages to us.
+	if err := t.session.Send(ctx, stanza.Presence{Type: stanza.AvailablePresence}.Wrap(nil)); err != nil {
+		return fmt.Errorf("xmpp: sending initial presence: %w", err)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- t.session.Serve(xmpp.HandlerFunc(func(tr xmlstream.TokenReadEncoder, start *xml.StartElement) error {
+			if start.Name.Local != "message" {
+				return nil
+			}
+
+			var msg messageBody
+			if err := xml.NewTokenDecoder(tr).DecodeElement(&msg, start); err != nil && err != io.EOF {
+				return nil
+			}
+			if msg.Body == "" || msg.Type != stanza.ChatMessage || t.handler == nil {
+				return nil
+			}
+
+			t.handler(ctx, chat.Message{
+				UserID:  UserID(msg.From),
+				ConvoID: msg.From.Bare().String(),
+				Text:    msg.Body,
+			})
+			return nil
+		}))
+	}()
+
+	select {
+	case <-ctx.Done():
+		_ = t.session.Close()
+		return ctx.Err()
+	case err := <-errCh:
+		return err
+	}
+}
+
+// Reply implements chat.Transport.
+func (t *Transport) Reply(ctx context.Context, convoID, text string) error {
+	to, err := jid.Parse(convoID)
+	if err != nil {
+		return fmt.Errorf("xmpp: invalid recipient %q: %w", convoID, err)
+	}
+	reply := messageBody{
+		Message: stanza.Message{To: to, Type: stanza.ChatMessage},
+		Body:    text,
+	}
+	if err := t.session.Encode(ctx, reply); err != nil {
+		return fmt.Errorf("xmpp: sending message: %w", err)
+	}
+	return nil
+}
+
+// StreamReply implements chat.Transport. XMPP has no notion of editing a
+// sent message in place, so the writer buffers everything written to it and
+// sends a single message stanza when Close is called.
+func (t *Transport) StreamReply(ctx context.Context, convoID string) io.WriteCloser {
+	return &streamWriter{ctx: ctx, transport: t, convoID: convoID}
+}
+
+// Presence implements chat.Transport. Looking up another entity's presence
+// requires a subscription and a locally cached roster, which this transport
+// doesn't maintain, so this always returns "".
+func (t *Transport) Presence(string) string {
+	return ""
+}
+
+type streamWriter struct {
+	ctx       context.Context
+	transport *Transport
+	convoID   string
+	buf       []byte
+}
+
+func (w *streamWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	return len(p), nil
+}
+
+func (w *streamWriter) Close() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	return w.transport.Reply(w.ctx, w.convoID, string(w.buf))
+}
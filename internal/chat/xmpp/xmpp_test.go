@@ -0,0 +1,17 @@
+package xmpp
+
+import (
+	"testing"
+
+	"mellium.im/xmpp/jid"
+)
+
+func TestUserID(t *testing.T) {
+	j, err := jid.Parse("alice@example.com/resource")
+	if err != nil {
+		t.Fatalf("jid.Parse failed: %v", err)
+	}
+	if got, want := UserID(j), "xmpp-user-alice@example.com"; got != want {
+		t.Errorf("UserID(%q) = %q, want %q", j, got, want)
+	}
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AgentConfig describes a single named agent: a system prompt, the tools it
+// may call, and any files to fold into its system prompt as static context.
+type AgentConfig struct {
+	// Name identifies the agent, e.g. for `-a/--agent <name>` selection.
+	Name string `yaml:"name"`
+
+	// SystemPrompt is injected as the conversation's system message when
+	// this agent is bound.
+	SystemPrompt string `yaml:"system_prompt"`
+
+	// Tools names the built-in tools (see internal/agent/toolbox) this
+	// agent is allowed to call, e.g. "read_file", "modify_file",
+	// "list_dir", "dir_tree", "web_fetch", "run_shell".
+	Tools []string `yaml:"tools"`
+
+	// Files are paths whose contents are appended to SystemPrompt as
+	// reference context, so the agent starts every conversation already
+	// primed with them instead of having to read_file them on demand.
+	Files []string `yaml:"files"`
+}
+
+// agentsFile is the shape of an agents.yaml: a single file listing every
+// agent, unlike Load's one-model/persona-per-file directory convention.
+type agentsFile struct {
+	Agents []AgentConfig `yaml:"agents"`
+}
+
+// LoadAgents reads the agent definitions in path (e.g.
+// ~/.config/lambdachat/agents.yaml). A missing file returns an empty slice,
+// not an error, so running without one configured is a no-op.
+func LoadAgents(path string) ([]AgentConfig, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config: reading %s: %w", path, err)
+	}
+
+	var f agentsFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("config: parsing %s: %w", path, err)
+	}
+	return f.Agents, nil
+}
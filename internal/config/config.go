@@ -0,0 +1,159 @@
+// Package config loads personas, models, and agent bindings from a
+// directory of YAML files, à la LocalAI's model configs, so operators can
+// add or change them without recompiling.
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Parameters holds default generation parameters for a model. Fields are
+// pointers so an unset parameter can be told apart from an explicit zero.
+type Parameters struct {
+	Temperature *float64 `yaml:"temperature"`
+	TopP        *float64 `yaml:"top_p"`
+	MaxTokens   *int     `yaml:"max_tokens"`
+}
+
+// ModelConfig describes a single model entry.
+type ModelConfig struct {
+	// ID is the API identifier for the model.
+	ID string `yaml:"id"`
+
+	// DisplayName is a human-readable name for the model.
+	DisplayName string `yaml:"display_name"`
+
+	// Aliases are alternative names the model can be selected by.
+	Aliases []string `yaml:"aliases"`
+
+	// Provider names the ChatCompletionProvider that serves this model. If
+	// empty, the client's default provider is used.
+	Provider string `yaml:"provider"`
+
+	// ContextLength is the model's maximum context window, in tokens, shown
+	// alongside DisplayName when presenting the model for selection. Zero
+	// means unknown.
+	ContextLength int `yaml:"context_length"`
+
+	// Template names a built-in chat template (e.g. "chatml", "llama3",
+	// "mistral", "deepseek-r1") or, if it doesn't match one, is compiled as
+	// raw Jinja chat template source. If empty, one is auto-selected from ID.
+	Template string `yaml:"template"`
+
+	// Reasoning marks that Template (when raw Jinja source) wraps reasoning
+	// in "<think>...</think>" blocks that should be hidden from the user.
+	// Ignored when Template names a built-in, which already knows this.
+	Reasoning bool `yaml:"reasoning"`
+
+	// AutoPersona, if set, names a persona to switch to automatically when
+	// this model is selected.
+	AutoPersona string `yaml:"auto_persona"`
+
+	// Agent, if set, names a registered agent to bind automatically when
+	// this model is selected, making its tools available.
+	Agent string `yaml:"agent"`
+
+	// Fallbacks lists model IDs to transparently retry against, in order,
+	// when this model is unhealthy (see lambdachat's modelHealth tracking).
+	Fallbacks []string `yaml:"fallbacks"`
+
+	// Parameters are the default generation parameters for this model.
+	Parameters Parameters `yaml:"parameters"`
+}
+
+// PersonaConfig describes a single persona entry.
+type PersonaConfig struct {
+	// Name identifies the persona, e.g. for `/persona <name>` selection.
+	Name string `yaml:"name"`
+
+	// Aliases are alternative names the persona can be selected by.
+	Aliases []string `yaml:"aliases"`
+
+	// Description is a short human-readable summary shown alongside Name in
+	// GetAvailablePersonas. Defaults to Name if empty.
+	Description string `yaml:"description"`
+
+	// SystemPrompt is injected as the conversation's system message when
+	// this persona is active.
+	SystemPrompt string `yaml:"system_prompt"`
+}
+
+// Config is the set of models and personas loaded from a config directory.
+type Config struct {
+	Models   []ModelConfig
+	Personas []PersonaConfig
+}
+
+// entry is the shape of a single YAML config file: it defines exactly one
+// model or one persona.
+type entry struct {
+	Model   *ModelConfig   `yaml:"model"`
+	Persona *PersonaConfig `yaml:"persona"`
+}
+
+// Load reads every *.yaml/*.yml file in dir and returns the models and
+// personas they define. An empty dir returns an empty, zero-value Config so
+// callers can fall back to their own bundled defaults.
+func Load(dir string) (*Config, error) {
+	cfg := &Config{}
+	if dir == "" {
+		return cfg, nil
+	}
+
+	files, err := configFiles(dir)
+	if err != nil {
+		return nil, fmt.Errorf("config: reading %s: %w", dir, err)
+	}
+
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("config: reading %s: %w", path, err)
+		}
+
+		var e entry
+		if err := yaml.Unmarshal(data, &e); err != nil {
+			return nil, fmt.Errorf("config: parsing %s: %w", path, err)
+		}
+
+		switch {
+		case e.Model != nil && e.Persona != nil:
+			return nil, fmt.Errorf("config: %s defines both a model and a persona", path)
+		case e.Model != nil:
+			cfg.Models = append(cfg.Models, *e.Model)
+		case e.Persona != nil:
+			cfg.Personas = append(cfg.Personas, *e.Persona)
+		default:
+			return nil, fmt.Errorf("config: %s defines neither a model nor a persona", path)
+		}
+	}
+
+	return cfg, nil
+}
+
+// configFiles returns the *.yaml/*.yml files directly inside dir, sorted by
+// name so Load's output is deterministic.
+func configFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		ext := filepath.Ext(e.Name())
+		if ext == ".yaml" || ext == ".yml" {
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
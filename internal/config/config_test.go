@@ -0,0 +1,138 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatalf("writing %s: %v", name, err)
+	}
+}
+
+func TestLoadEmptyDir(t *testing.T) {
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load(\"\") failed: %v", err)
+	}
+	if len(cfg.Models) != 0 || len(cfg.Personas) != 0 {
+		t.Errorf("Expected an empty Config for an empty dir, got %+v", cfg)
+	}
+}
+
+func TestLoadModelsAndPersonas(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "model-a.yaml", `
+model:
+  id: model-a
+  display_name: Model A
+  provider: fake
+`)
+	writeFile(t, dir, "persona-bender.yml", `
+persona:
+  name: bender
+  system_prompt: Bite my shiny metal ass.
+`)
+	writeFile(t, dir, "README.md", "not a config file, should be ignored")
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(cfg.Models) != 1 || cfg.Models[0].ID != "model-a" {
+		t.Errorf("Expected one model %q, got %+v", "model-a", cfg.Models)
+	}
+	if len(cfg.Personas) != 1 || cfg.Personas[0].Name != "bender" {
+		t.Errorf("Expected one persona %q, got %+v", "bender", cfg.Personas)
+	}
+}
+
+func TestLoadRejectsAmbiguousEntry(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "both.yaml", `
+model:
+  id: model-a
+persona:
+  name: bender
+`)
+	if _, err := Load(dir); err == nil {
+		t.Fatalf("Expected an error for a file defining both a model and a persona")
+	}
+}
+
+func TestLoadRejectsEmptyEntry(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "empty.yaml", "{}")
+	if _, err := Load(dir); err == nil {
+		t.Fatalf("Expected an error for a file defining neither a model nor a persona")
+	}
+}
+
+func TestLoadRootConfigMissingFile(t *testing.T) {
+	cfg, err := LoadRootConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatalf("LoadRootConfig of a missing file should not error, got %v", err)
+	}
+	if cfg.DefaultProvider != "" || len(cfg.Providers) != 0 {
+		t.Errorf("Expected an empty RootConfig for a missing file, got %+v", cfg)
+	}
+}
+
+func TestLoadRootConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	writeFile(t, dir, "config.yaml", `
+default_provider: lambda
+providers:
+  lambda:
+    base_url: https://api.lambdalabs.com
+    api_key: secret
+    default_model: deepseek-llama3.3-70b
+`)
+
+	cfg, err := LoadRootConfig(path)
+	if err != nil {
+		t.Fatalf("LoadRootConfig failed: %v", err)
+	}
+	if cfg.DefaultProvider != "lambda" {
+		t.Errorf("DefaultProvider = %q, want %q", cfg.DefaultProvider, "lambda")
+	}
+	pc, ok := cfg.Providers["lambda"]
+	if !ok || pc.BaseURL != "https://api.lambdalabs.com" || pc.DefaultModel != "deepseek-llama3.3-70b" {
+		t.Errorf("Providers[\"lambda\"] = %+v, ok=%v", pc, ok)
+	}
+}
+
+func TestLoadAgentsMissingFile(t *testing.T) {
+	agents, err := LoadAgents(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatalf("LoadAgents of a missing file should not error, got %v", err)
+	}
+	if agents != nil {
+		t.Errorf("Expected a nil slice for a missing agents file, got %+v", agents)
+	}
+}
+
+func TestLoadAgents(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agents.yaml")
+	writeFile(t, dir, "agents.yaml", `
+agents:
+  - name: coder
+    system_prompt: You write code.
+    tools:
+      - read_file
+      - modify_file
+`)
+
+	agents, err := LoadAgents(path)
+	if err != nil {
+		t.Fatalf("LoadAgents failed: %v", err)
+	}
+	if len(agents) != 1 || agents[0].Name != "coder" || len(agents[0].Tools) != 2 {
+		t.Errorf("Expected one agent %q with 2 tools, got %+v", "coder", agents)
+	}
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProviderConfig names an OpenAI-compatible (or provider-specific) endpoint
+// the CLI can talk to, so the same binary can point at Lambda Chat, a local
+// vLLM/Ollama, or any other compatible backend without a recompile.
+type ProviderConfig struct {
+	BaseURL      string            `yaml:"base_url"`
+	APIKey       string            `yaml:"api_key"`
+	DefaultModel string            `yaml:"default_model"`
+	Headers      map[string]string `yaml:"headers"`
+}
+
+// RootConfig is the shape of ~/.config/lambdachat/config.yaml: a set of
+// named providers and which one to use by default.
+type RootConfig struct {
+	DefaultProvider string                    `yaml:"default_provider"`
+	Providers       map[string]ProviderConfig `yaml:"providers"`
+}
+
+// LoadRootConfig reads the root config at path (e.g.
+// ~/.config/lambdachat/config.yaml). A missing file returns an empty,
+// non-nil RootConfig rather than an error, so running without one
+// configured falls back to built-in defaults.
+func LoadRootConfig(path string) (*RootConfig, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &RootConfig{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config: reading %s: %w", path, err)
+	}
+
+	var cfg RootConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("config: parsing %s: %w", path, err)
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,105 @@
+// Package convstore persists per-user conversations as a message tree, so
+// that a user's history can be branched (Fork) or rewritten-and-reprompted
+// (Edit) instead of living only as a flat, in-memory slice.
+package convstore
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// Message is a single node in a user's conversation tree.
+type Message struct {
+	ID        string
+	ParentID  string // empty for the conversation's root message
+	Role      string
+	Content   string
+	Model     string
+	CreatedAt time.Time
+}
+
+// ConversationMeta summarizes a conversation for listing.
+type ConversationMeta struct {
+	ID        string
+	Title     string
+	LeafID    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ConversationStore persists per-user conversations as a message tree,
+// allowing branching (Fork) and rewriting-and-reprompting (Edit).
+type ConversationStore interface {
+	// NewConversation creates an empty conversation for userID and returns its ID.
+	NewConversation(userID string) (string, error)
+
+	// AppendMessage appends msg as a child of parentID (empty to start the
+	// conversation's root) and advances the conversation's leaf to the new
+	// message, returning it with its assigned ID and timestamp.
+	AppendMessage(userID, convoID, parentID string, msg Message) (Message, error)
+
+	// History returns the linear path of messages from the conversation's
+	// root to its current leaf.
+	History(userID, convoID string) ([]Message, error)
+
+	// Leaf returns the ID of the message at the tip of a conversation's
+	// current branch, so callers can thread AppendMessage calls off of it.
+	Leaf(userID, convoID string) (string, error)
+
+	// Fork creates a new conversation that shares history up to and
+	// including messageID, returning the new conversation's ID.
+	Fork(userID, convoID, messageID string) (string, error)
+
+	// Edit rewrites messageID's content, discarding any messages that came
+	// after it, and returns the rewritten message so the caller can
+	// re-prompt the model from that point.
+	Edit(userID, convoID, messageID, newContent string) (Message, error)
+
+	// Extend appends additionalContent onto messageID's existing content and
+	// returns the updated message, used to continue a reply that was cut
+	// short instead of starting a new assistant turn.
+	Extend(userID, convoID, messageID, additionalContent string) (Message, error)
+
+	// List returns metadata for every conversation belonging to userID,
+	// most recently updated first.
+	List(userID string) ([]ConversationMeta, error)
+
+	// Delete removes a conversation and all of its messages.
+	Delete(userID, convoID string) error
+
+	// Rename sets a conversation's display title, overriding its
+	// auto-generated one.
+	Rename(userID, convoID, title string) error
+
+	// SavePreference persists a per-user key/value setting (e.g. preferred
+	// model or persona name) so it survives a restart.
+	SavePreference(userID, key, value string) error
+
+	// LoadPreferences returns every preference saved for userID, keyed by
+	// the key passed to SavePreference. It returns an empty map, not an
+	// error, for a user with none saved.
+	LoadPreferences(userID string) (map[string]string, error)
+}
+
+// newID returns a short random hex identifier for a conversation or message.
+func newID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand.Read on the standard reader only fails if the OS
+		// entropy source is broken, which is unrecoverable anyway.
+		panic(fmt.Sprintf("convstore: failed to generate ID: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// ErrNotFound is returned when a conversation or message does not exist.
+type ErrNotFound struct {
+	Kind string
+	ID   string
+}
+
+func (e *ErrNotFound) Error() string {
+	return fmt.Sprintf("convstore: %s %q not found", e.Kind, e.ID)
+}
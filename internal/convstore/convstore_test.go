@@ -0,0 +1,223 @@
+package convstore
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// newStores returns one of each ConversationStore backend, so every test
+// below runs identically against both: they're expected to behave the same
+// way from the interface's perspective.
+func newStores(t *testing.T) map[string]ConversationStore {
+	t.Helper()
+
+	sqlite, err := NewSQLite(filepath.Join(t.TempDir(), "convstore.db"))
+	if err != nil {
+		t.Fatalf("NewSQLite failed: %v", err)
+	}
+
+	return map[string]ConversationStore{
+		"memory": NewMemory(),
+		"sqlite": sqlite,
+	}
+}
+
+func forEachStore(t *testing.T, run func(t *testing.T, store ConversationStore)) {
+	for name, store := range newStores(t) {
+		t.Run(name, func(t *testing.T) {
+			run(t, store)
+		})
+	}
+}
+
+func TestAppendMessageAndHistory(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store ConversationStore) {
+		convoID, err := store.NewConversation("alice")
+		if err != nil {
+			t.Fatalf("NewConversation failed: %v", err)
+		}
+
+		root, err := store.AppendMessage("alice", convoID, "", Message{Role: "user", Content: "hi"})
+		if err != nil {
+			t.Fatalf("AppendMessage (root) failed: %v", err)
+		}
+		if root.ID == "" {
+			t.Fatalf("Expected AppendMessage to assign an ID")
+		}
+
+		reply, err := store.AppendMessage("alice", convoID, root.ID, Message{Role: "assistant", Content: "hello"})
+		if err != nil {
+			t.Fatalf("AppendMessage (reply) failed: %v", err)
+		}
+
+		history, err := store.History("alice", convoID)
+		if err != nil {
+			t.Fatalf("History failed: %v", err)
+		}
+		if len(history) != 2 || history[0].Content != "hi" || history[1].Content != "hello" {
+			t.Errorf("History() = %+v, want [hi, hello]", history)
+		}
+
+		leaf, err := store.Leaf("alice", convoID)
+		if err != nil {
+			t.Fatalf("Leaf failed: %v", err)
+		}
+		if leaf != reply.ID {
+			t.Errorf("Leaf() = %q, want the most recent message %q", leaf, reply.ID)
+		}
+	})
+}
+
+func TestFork(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store ConversationStore) {
+		convoID, _ := store.NewConversation("alice")
+		root, _ := store.AppendMessage("alice", convoID, "", Message{Role: "user", Content: "hi"})
+		_, _ = store.AppendMessage("alice", convoID, root.ID, Message{Role: "assistant", Content: "hello"})
+
+		forkID, err := store.Fork("alice", convoID, root.ID)
+		if err != nil {
+			t.Fatalf("Fork failed: %v", err)
+		}
+		if forkID == convoID {
+			t.Fatalf("Fork should return a new conversation ID")
+		}
+
+		history, err := store.History("alice", forkID)
+		if err != nil {
+			t.Fatalf("History of the fork failed: %v", err)
+		}
+		if len(history) != 1 || history[0].Content != "hi" {
+			t.Errorf("History(fork) = %+v, want only the root message", history)
+		}
+
+		// The original conversation must be unaffected by the fork.
+		original, err := store.History("alice", convoID)
+		if err != nil || len(original) != 2 {
+			t.Errorf("History(original) = %+v, err=%v; the fork shouldn't have touched it", original, err)
+		}
+	})
+}
+
+func TestEditDiscardsLaterMessages(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store ConversationStore) {
+		convoID, _ := store.NewConversation("alice")
+		root, _ := store.AppendMessage("alice", convoID, "", Message{Role: "user", Content: "hi"})
+		reply, _ := store.AppendMessage("alice", convoID, root.ID, Message{Role: "assistant", Content: "hello"})
+		_, _ = store.AppendMessage("alice", convoID, reply.ID, Message{Role: "user", Content: "follow-up"})
+
+		edited, err := store.Edit("alice", convoID, root.ID, "hi, edited")
+		if err != nil {
+			t.Fatalf("Edit failed: %v", err)
+		}
+		if edited.Content != "hi, edited" {
+			t.Errorf("Edit() content = %q, want %q", edited.Content, "hi, edited")
+		}
+
+		history, err := store.History("alice", convoID)
+		if err != nil {
+			t.Fatalf("History after Edit failed: %v", err)
+		}
+		if len(history) != 1 || history[0].Content != "hi, edited" {
+			t.Errorf("Expected Edit to discard every message after it, got %+v", history)
+		}
+	})
+}
+
+func TestExtendAppendsToExistingMessage(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store ConversationStore) {
+		convoID, _ := store.NewConversation("alice")
+		root, _ := store.AppendMessage("alice", convoID, "", Message{Role: "user", Content: "hi"})
+		reply, _ := store.AppendMessage("alice", convoID, root.ID, Message{Role: "assistant", Content: "par"})
+
+		extended, err := store.Extend("alice", convoID, reply.ID, "tial")
+		if err != nil {
+			t.Fatalf("Extend failed: %v", err)
+		}
+		if extended.Content != "partial" {
+			t.Errorf("Extend() content = %q, want %q", extended.Content, "partial")
+		}
+	})
+}
+
+func TestListAndDelete(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store ConversationStore) {
+		convoA, _ := store.NewConversation("alice")
+		_, _ = store.NewConversation("alice")
+
+		metas, err := store.List("alice")
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		if len(metas) != 2 {
+			t.Fatalf("List() returned %d conversations, want 2", len(metas))
+		}
+
+		if err := store.Delete("alice", convoA); err != nil {
+			t.Fatalf("Delete failed: %v", err)
+		}
+		metas, err = store.List("alice")
+		if err != nil {
+			t.Fatalf("List after Delete failed: %v", err)
+		}
+		if len(metas) != 1 {
+			t.Errorf("List() after Delete returned %d conversations, want 1", len(metas))
+		}
+	})
+}
+
+func TestRename(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store ConversationStore) {
+		convoID, _ := store.NewConversation("alice")
+		if err := store.Rename("alice", convoID, "My Chat"); err != nil {
+			t.Fatalf("Rename failed: %v", err)
+		}
+		metas, err := store.List("alice")
+		if err != nil || len(metas) != 1 || metas[0].Title != "My Chat" {
+			t.Errorf("List() = %+v, err=%v; want a single conversation titled %q", metas, err, "My Chat")
+		}
+	})
+}
+
+func TestPreferences(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store ConversationStore) {
+		prefs, err := store.LoadPreferences("alice")
+		if err != nil {
+			t.Fatalf("LoadPreferences (unset) failed: %v", err)
+		}
+		if len(prefs) != 0 {
+			t.Errorf("Expected no preferences for a fresh user, got %+v", prefs)
+		}
+
+		if err := store.SavePreference("alice", "model", "deepseek-llama3.3-70b"); err != nil {
+			t.Fatalf("SavePreference failed: %v", err)
+		}
+		if err := store.SavePreference("alice", "persona", "bender"); err != nil {
+			t.Fatalf("SavePreference failed: %v", err)
+		}
+
+		prefs, err = store.LoadPreferences("alice")
+		if err != nil {
+			t.Fatalf("LoadPreferences failed: %v", err)
+		}
+		if prefs["model"] != "deepseek-llama3.3-70b" || prefs["persona"] != "bender" {
+			t.Errorf("LoadPreferences() = %+v, missing expected keys", prefs)
+		}
+
+		// Saving again for the same key should overwrite, not duplicate.
+		if err := store.SavePreference("alice", "model", "gpt-4o"); err != nil {
+			t.Fatalf("SavePreference (overwrite) failed: %v", err)
+		}
+		prefs, _ = store.LoadPreferences("alice")
+		if prefs["model"] != "gpt-4o" {
+			t.Errorf("Expected SavePreference to overwrite, got %+v", prefs)
+		}
+	})
+}
+
+func TestHistoryUnknownConversation(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store ConversationStore) {
+		if _, err := store.History("alice", "no-such-convo"); err == nil {
+			t.Errorf("Expected an error for an unknown conversation")
+		}
+	})
+}
@@ -0,0 +1,298 @@
+package convstore
+
+import (
+	"sync"
+	"time"
+)
+
+// conversation is the in-memory representation of a single conversation tree.
+type conversation struct {
+	meta     ConversationMeta
+	messages map[string]Message // messageID -> Message
+}
+
+// memoryStore is an in-memory ConversationStore, used for tests and as a
+// fallback when no persistent store is configured.
+type memoryStore struct {
+	mu            sync.RWMutex
+	conversations map[string]map[string]*conversation // userID -> convoID -> conversation
+	preferences   map[string]map[string]string        // userID -> key -> value
+}
+
+// NewMemory creates an in-memory ConversationStore.
+func NewMemory() ConversationStore {
+	return &memoryStore{
+		conversations: make(map[string]map[string]*conversation),
+		preferences:   make(map[string]map[string]string),
+	}
+}
+
+func (s *memoryStore) NewConversation(userID string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conversations[userID] == nil {
+		s.conversations[userID] = make(map[string]*conversation)
+	}
+
+	id := newID()
+	now := time.Now()
+	s.conversations[userID][id] = &conversation{
+		meta: ConversationMeta{
+			ID:        id,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		messages: make(map[string]Message),
+	}
+	return id, nil
+}
+
+func (s *memoryStore) AppendMessage(userID, convoID, parentID string, msg Message) (Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	convo, err := s.get(userID, convoID)
+	if err != nil {
+		return Message{}, err
+	}
+
+	if parentID != "" {
+		if _, ok := convo.messages[parentID]; !ok {
+			return Message{}, &ErrNotFound{Kind: "message", ID: parentID}
+		}
+	}
+
+	msg.ID = newID()
+	msg.ParentID = parentID
+	msg.CreatedAt = time.Now()
+
+	convo.messages[msg.ID] = msg
+	convo.meta.LeafID = msg.ID
+	convo.meta.UpdatedAt = msg.CreatedAt
+	if convo.meta.Title == "" && msg.Role == "user" {
+		convo.meta.Title = title(msg.Content)
+	}
+
+	return msg, nil
+}
+
+func (s *memoryStore) History(userID, convoID string) ([]Message, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	convo, err := s.get(userID, convoID)
+	if err != nil {
+		return nil, err
+	}
+
+	return pathTo(convo.messages, convo.meta.LeafID), nil
+}
+
+func (s *memoryStore) Leaf(userID, convoID string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	convo, err := s.get(userID, convoID)
+	if err != nil {
+		return "", err
+	}
+	return convo.meta.LeafID, nil
+}
+
+func (s *memoryStore) Fork(userID, convoID, messageID string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	convo, err := s.get(userID, convoID)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := convo.messages[messageID]; !ok {
+		return "", &ErrNotFound{Kind: "message", ID: messageID}
+	}
+
+	newID := newID()
+	now := time.Now()
+	forked := &conversation{
+		meta: ConversationMeta{
+			ID:        newID,
+			Title:     convo.meta.Title,
+			LeafID:    messageID,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		messages: make(map[string]Message, len(convo.messages)),
+	}
+	for id, msg := range convo.messages {
+		forked.messages[id] = msg
+	}
+
+	s.conversations[userID][newID] = forked
+	return newID, nil
+}
+
+func (s *memoryStore) Edit(userID, convoID, messageID, newContent string) (Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	convo, err := s.get(userID, convoID)
+	if err != nil {
+		return Message{}, err
+	}
+
+	msg, ok := convo.messages[messageID]
+	if !ok {
+		return Message{}, &ErrNotFound{Kind: "message", ID: messageID}
+	}
+
+	// Drop every message that isn't an ancestor of (or is) messageID, so
+	// the edit truncates the branch rather than leaving orphaned children.
+	kept := pathTo(convo.messages, messageID)
+	convo.messages = make(map[string]Message, len(kept))
+	for _, m := range kept {
+		convo.messages[m.ID] = m
+	}
+
+	msg.Content = newContent
+	convo.messages[messageID] = msg
+	convo.meta.LeafID = messageID
+	convo.meta.UpdatedAt = time.Now()
+
+	return msg, nil
+}
+
+func (s *memoryStore) Extend(userID, convoID, messageID, additionalContent string) (Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	convo, err := s.get(userID, convoID)
+	if err != nil {
+		return Message{}, err
+	}
+
+	msg, ok := convo.messages[messageID]
+	if !ok {
+		return Message{}, &ErrNotFound{Kind: "message", ID: messageID}
+	}
+
+	msg.Content += additionalContent
+	convo.messages[messageID] = msg
+	convo.meta.UpdatedAt = time.Now()
+
+	return msg, nil
+}
+
+func (s *memoryStore) List(userID string) ([]ConversationMeta, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	convos := s.conversations[userID]
+	metas := make([]ConversationMeta, 0, len(convos))
+	for _, convo := range convos {
+		metas = append(metas, convo.meta)
+	}
+
+	sortByUpdatedDesc(metas)
+	return metas, nil
+}
+
+func (s *memoryStore) Delete(userID, convoID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.get(userID, convoID); err != nil {
+		return err
+	}
+	delete(s.conversations[userID], convoID)
+	return nil
+}
+
+func (s *memoryStore) Rename(userID, convoID, title string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	convo, err := s.get(userID, convoID)
+	if err != nil {
+		return err
+	}
+	convo.meta.Title = title
+	convo.meta.UpdatedAt = time.Now()
+	return nil
+}
+
+func (s *memoryStore) SavePreference(userID, key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.preferences[userID] == nil {
+		s.preferences[userID] = make(map[string]string)
+	}
+	s.preferences[userID][key] = value
+	return nil
+}
+
+func (s *memoryStore) LoadPreferences(userID string) (map[string]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	prefs := make(map[string]string, len(s.preferences[userID]))
+	for k, v := range s.preferences[userID] {
+		prefs[k] = v
+	}
+	return prefs, nil
+}
+
+// get returns the conversation for userID/convoID, holding the caller's lock.
+func (s *memoryStore) get(userID, convoID string) (*conversation, error) {
+	convos, ok := s.conversations[userID]
+	if !ok {
+		return nil, &ErrNotFound{Kind: "conversation", ID: convoID}
+	}
+	convo, ok := convos[convoID]
+	if !ok {
+		return nil, &ErrNotFound{Kind: "conversation", ID: convoID}
+	}
+	return convo, nil
+}
+
+// pathTo walks parent links from leafID back to the root and returns the
+// messages in root-to-leaf order. It returns nil if leafID is empty or unknown.
+func pathTo(messages map[string]Message, leafID string) []Message {
+	if leafID == "" {
+		return nil
+	}
+
+	var reversed []Message
+	for id := leafID; id != ""; {
+		msg, ok := messages[id]
+		if !ok {
+			break
+		}
+		reversed = append(reversed, msg)
+		id = msg.ParentID
+	}
+
+	path := make([]Message, len(reversed))
+	for i, msg := range reversed {
+		path[len(reversed)-1-i] = msg
+	}
+	return path
+}
+
+// title derives a short conversation title from its first user message.
+func title(content string) string {
+	const maxLen = 60
+	if len(content) <= maxLen {
+		return content
+	}
+	return content[:maxLen] + "..."
+}
+
+func sortByUpdatedDesc(metas []ConversationMeta) {
+	for i := 1; i < len(metas); i++ {
+		for j := i; j > 0 && metas[j].UpdatedAt.After(metas[j-1].UpdatedAt); j-- {
+			metas[j], metas[j-1] = metas[j-1], metas[j]
+		}
+	}
+}
@@ -0,0 +1,443 @@
+package convstore
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" database/sql driver
+)
+
+// sqliteStore is a ConversationStore backed by a SQLite database, used as
+// the default persistent store so conversations survive a restart.
+type sqliteStore struct {
+	db *sql.DB
+}
+
+// NewSQLite opens (creating if necessary) a SQLite-backed ConversationStore
+// at path.
+func NewSQLite(path string) (ConversationStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("convstore: opening sqlite database: %w", err)
+	}
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("convstore: creating schema: %w", err)
+	}
+
+	return &sqliteStore{db: db}, nil
+}
+
+const schema = `
+CREATE TABLE IF NOT EXISTS conversations (
+	id TEXT PRIMARY KEY,
+	user_id TEXT NOT NULL,
+	title TEXT NOT NULL DEFAULT '',
+	leaf_id TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_conversations_user ON conversations(user_id);
+
+CREATE TABLE IF NOT EXISTS messages (
+	id TEXT PRIMARY KEY,
+	convo_id TEXT NOT NULL,
+	parent_id TEXT NOT NULL DEFAULT '',
+	role TEXT NOT NULL,
+	content TEXT NOT NULL,
+	model TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_messages_convo ON messages(convo_id);
+
+CREATE TABLE IF NOT EXISTS preferences (
+	user_id TEXT NOT NULL,
+	key TEXT NOT NULL,
+	value TEXT NOT NULL,
+	PRIMARY KEY (user_id, key)
+);
+`
+
+func (s *sqliteStore) NewConversation(userID string) (string, error) {
+	id := newID()
+	now := time.Now()
+
+	_, err := s.db.Exec(
+		`INSERT INTO conversations (id, user_id, title, leaf_id, created_at, updated_at) VALUES (?, ?, '', '', ?, ?)`,
+		id, userID, now, now,
+	)
+	if err != nil {
+		return "", fmt.Errorf("convstore: creating conversation: %w", err)
+	}
+	return id, nil
+}
+
+func (s *sqliteStore) AppendMessage(userID, convoID, parentID string, msg Message) (Message, error) {
+	if err := s.mustOwn(userID, convoID); err != nil {
+		return Message{}, err
+	}
+
+	if parentID != "" {
+		var exists int
+		if err := s.db.QueryRow(`SELECT 1 FROM messages WHERE id = ? AND convo_id = ?`, parentID, convoID).Scan(&exists); err != nil {
+			if err == sql.ErrNoRows {
+				return Message{}, &ErrNotFound{Kind: "message", ID: parentID}
+			}
+			return Message{}, fmt.Errorf("convstore: looking up parent message: %w", err)
+		}
+	}
+
+	msg.ID = newID()
+	msg.ParentID = parentID
+	msg.CreatedAt = time.Now()
+
+	if _, err := s.db.Exec(
+		`INSERT INTO messages (id, convo_id, parent_id, role, content, model, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		msg.ID, convoID, msg.ParentID, msg.Role, msg.Content, msg.Model, msg.CreatedAt,
+	); err != nil {
+		return Message{}, fmt.Errorf("convstore: appending message: %w", err)
+	}
+
+	var title string
+	if err := s.db.QueryRow(`SELECT title FROM conversations WHERE id = ?`, convoID).Scan(&title); err != nil {
+		return Message{}, fmt.Errorf("convstore: reading conversation title: %w", err)
+	}
+	if title == "" && msg.Role == "user" {
+		title = titleFor(msg.Content)
+	}
+
+	if _, err := s.db.Exec(
+		`UPDATE conversations SET leaf_id = ?, title = ?, updated_at = ? WHERE id = ?`,
+		msg.ID, title, msg.CreatedAt, convoID,
+	); err != nil {
+		return Message{}, fmt.Errorf("convstore: updating conversation: %w", err)
+	}
+
+	return msg, nil
+}
+
+func (s *sqliteStore) History(userID, convoID string) ([]Message, error) {
+	if err := s.mustOwn(userID, convoID); err != nil {
+		return nil, err
+	}
+
+	var leafID string
+	if err := s.db.QueryRow(`SELECT leaf_id FROM conversations WHERE id = ?`, convoID).Scan(&leafID); err != nil {
+		return nil, fmt.Errorf("convstore: reading conversation: %w", err)
+	}
+
+	messages, err := s.loadMessages(convoID)
+	if err != nil {
+		return nil, err
+	}
+	return pathTo(messages, leafID), nil
+}
+
+func (s *sqliteStore) Leaf(userID, convoID string) (string, error) {
+	if err := s.mustOwn(userID, convoID); err != nil {
+		return "", err
+	}
+	var leafID string
+	if err := s.db.QueryRow(`SELECT leaf_id FROM conversations WHERE id = ?`, convoID).Scan(&leafID); err != nil {
+		return "", fmt.Errorf("convstore: reading leaf: %w", err)
+	}
+	return leafID, nil
+}
+
+func (s *sqliteStore) Fork(userID, convoID, messageID string) (string, error) {
+	if err := s.mustOwn(userID, convoID); err != nil {
+		return "", err
+	}
+
+	var title string
+	if err := s.db.QueryRow(
+		`SELECT c.title FROM conversations c JOIN messages m ON m.convo_id = c.id WHERE c.id = ? AND m.id = ?`,
+		convoID, messageID,
+	).Scan(&title); err != nil {
+		if err == sql.ErrNoRows {
+			return "", &ErrNotFound{Kind: "message", ID: messageID}
+		}
+		return "", fmt.Errorf("convstore: looking up message: %w", err)
+	}
+
+	newConvoID := newID()
+	now := time.Now()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return "", fmt.Errorf("convstore: starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		`INSERT INTO conversations (id, user_id, title, leaf_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		newConvoID, userID, title, "", now, now,
+	); err != nil {
+		return "", fmt.Errorf("convstore: creating forked conversation: %w", err)
+	}
+
+	// messages.id is a global primary key, so the copy can't reuse the
+	// source conversation's IDs; remap each one to a fresh ID, threading the
+	// remap through parent_id so the copied tree's shape is preserved.
+	rows, err := tx.Query(
+		`SELECT id, parent_id, role, content, model, created_at FROM messages WHERE convo_id = ? ORDER BY rowid`,
+		convoID,
+	)
+	if err != nil {
+		return "", fmt.Errorf("convstore: reading messages to fork: %w", err)
+	}
+	defer rows.Close()
+
+	remap := make(map[string]string)
+	var newLeafID string
+	for rows.Next() {
+		var oldID, parentID, role, content, model string
+		var createdAt time.Time
+		if err := rows.Scan(&oldID, &parentID, &role, &content, &model, &createdAt); err != nil {
+			return "", fmt.Errorf("convstore: scanning message to fork: %w", err)
+		}
+
+		newMsgID := newID()
+		remap[oldID] = newMsgID
+		if oldID == messageID {
+			newLeafID = newMsgID
+		}
+
+		newParentID := ""
+		if parentID != "" {
+			// Messages are returned in insertion order, so a row's parent
+			// was already remapped by the time it's copied here.
+			newParentID = remap[parentID]
+		}
+
+		if _, err := tx.Exec(
+			`INSERT INTO messages (id, convo_id, parent_id, role, content, model, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			newMsgID, newConvoID, newParentID, role, content, model, createdAt,
+		); err != nil {
+			return "", fmt.Errorf("convstore: copying message: %w", err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("convstore: reading messages to fork: %w", err)
+	}
+	rows.Close()
+
+	if _, err := tx.Exec(`UPDATE conversations SET leaf_id = ? WHERE id = ?`, newLeafID, newConvoID); err != nil {
+		return "", fmt.Errorf("convstore: setting forked conversation's leaf: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("convstore: committing fork: %w", err)
+	}
+	return newConvoID, nil
+}
+
+func (s *sqliteStore) Edit(userID, convoID, messageID, newContent string) (Message, error) {
+	if err := s.mustOwn(userID, convoID); err != nil {
+		return Message{}, err
+	}
+
+	messages, err := s.loadMessages(convoID)
+	if err != nil {
+		return Message{}, err
+	}
+
+	kept := pathTo(messages, messageID)
+	if len(kept) == 0 {
+		return Message{}, &ErrNotFound{Kind: "message", ID: messageID}
+	}
+	msg := kept[len(kept)-1]
+	msg.Content = newContent
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return Message{}, fmt.Errorf("convstore: starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM messages WHERE convo_id = ?`, convoID); err != nil {
+		return Message{}, fmt.Errorf("convstore: truncating branch: %w", err)
+	}
+	for _, m := range kept {
+		content := m.Content
+		if m.ID == messageID {
+			content = newContent
+		}
+		if _, err := tx.Exec(
+			`INSERT INTO messages (id, convo_id, parent_id, role, content, model, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			m.ID, convoID, m.ParentID, m.Role, content, m.Model, m.CreatedAt,
+		); err != nil {
+			return Message{}, fmt.Errorf("convstore: rewriting branch: %w", err)
+		}
+	}
+	if _, err := tx.Exec(
+		`UPDATE conversations SET leaf_id = ?, updated_at = ? WHERE id = ?`,
+		messageID, time.Now(), convoID,
+	); err != nil {
+		return Message{}, fmt.Errorf("convstore: updating conversation: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Message{}, fmt.Errorf("convstore: committing edit: %w", err)
+	}
+	return msg, nil
+}
+
+func (s *sqliteStore) Extend(userID, convoID, messageID, additionalContent string) (Message, error) {
+	if err := s.mustOwn(userID, convoID); err != nil {
+		return Message{}, err
+	}
+
+	var m Message
+	if err := s.db.QueryRow(
+		`SELECT id, parent_id, role, content, model, created_at FROM messages WHERE id = ? AND convo_id = ?`,
+		messageID, convoID,
+	).Scan(&m.ID, &m.ParentID, &m.Role, &m.Content, &m.Model, &m.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return Message{}, &ErrNotFound{Kind: "message", ID: messageID}
+		}
+		return Message{}, fmt.Errorf("convstore: looking up message: %w", err)
+	}
+	m.Content += additionalContent
+
+	now := time.Now()
+	if _, err := s.db.Exec(`UPDATE messages SET content = ? WHERE id = ?`, m.Content, messageID); err != nil {
+		return Message{}, fmt.Errorf("convstore: extending message: %w", err)
+	}
+	if _, err := s.db.Exec(`UPDATE conversations SET updated_at = ? WHERE id = ?`, now, convoID); err != nil {
+		return Message{}, fmt.Errorf("convstore: updating conversation: %w", err)
+	}
+
+	return m, nil
+}
+
+func (s *sqliteStore) List(userID string) ([]ConversationMeta, error) {
+	rows, err := s.db.Query(
+		`SELECT id, title, leaf_id, created_at, updated_at FROM conversations WHERE user_id = ? ORDER BY updated_at DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("convstore: listing conversations: %w", err)
+	}
+	defer rows.Close()
+
+	var metas []ConversationMeta
+	for rows.Next() {
+		var m ConversationMeta
+		if err := rows.Scan(&m.ID, &m.Title, &m.LeafID, &m.CreatedAt, &m.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("convstore: scanning conversation: %w", err)
+		}
+		metas = append(metas, m)
+	}
+	return metas, rows.Err()
+}
+
+func (s *sqliteStore) Delete(userID, convoID string) error {
+	if err := s.mustOwn(userID, convoID); err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("convstore: starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM messages WHERE convo_id = ?`, convoID); err != nil {
+		return fmt.Errorf("convstore: deleting messages: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM conversations WHERE id = ?`, convoID); err != nil {
+		return fmt.Errorf("convstore: deleting conversation: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (s *sqliteStore) Rename(userID, convoID, title string) error {
+	if err := s.mustOwn(userID, convoID); err != nil {
+		return err
+	}
+
+	if _, err := s.db.Exec(
+		`UPDATE conversations SET title = ?, updated_at = ? WHERE id = ?`,
+		title, time.Now(), convoID,
+	); err != nil {
+		return fmt.Errorf("convstore: renaming conversation: %w", err)
+	}
+	return nil
+}
+
+func (s *sqliteStore) SavePreference(userID, key, value string) error {
+	if _, err := s.db.Exec(
+		`INSERT INTO preferences (user_id, key, value) VALUES (?, ?, ?)
+		 ON CONFLICT (user_id, key) DO UPDATE SET value = excluded.value`,
+		userID, key, value,
+	); err != nil {
+		return fmt.Errorf("convstore: saving preference: %w", err)
+	}
+	return nil
+}
+
+func (s *sqliteStore) LoadPreferences(userID string) (map[string]string, error) {
+	rows, err := s.db.Query(`SELECT key, value FROM preferences WHERE user_id = ?`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("convstore: loading preferences: %w", err)
+	}
+	defer rows.Close()
+
+	prefs := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("convstore: scanning preference: %w", err)
+		}
+		prefs[key] = value
+	}
+	return prefs, rows.Err()
+}
+
+// mustOwn verifies that convoID exists and belongs to userID.
+func (s *sqliteStore) mustOwn(userID, convoID string) error {
+	var owner string
+	err := s.db.QueryRow(`SELECT user_id FROM conversations WHERE id = ?`, convoID).Scan(&owner)
+	if err == sql.ErrNoRows {
+		return &ErrNotFound{Kind: "conversation", ID: convoID}
+	}
+	if err != nil {
+		return fmt.Errorf("convstore: looking up conversation: %w", err)
+	}
+	if owner != userID {
+		return &ErrNotFound{Kind: "conversation", ID: convoID}
+	}
+	return nil
+}
+
+// loadMessages returns every message in a conversation, keyed by ID.
+func (s *sqliteStore) loadMessages(convoID string) (map[string]Message, error) {
+	rows, err := s.db.Query(
+		`SELECT id, parent_id, role, content, model, created_at FROM messages WHERE convo_id = ?`,
+		convoID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("convstore: loading messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := make(map[string]Message)
+	for rows.Next() {
+		var m Message
+		if err := rows.Scan(&m.ID, &m.ParentID, &m.Role, &m.Content, &m.Model, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("convstore: scanning message: %w", err)
+		}
+		messages[m.ID] = m
+	}
+	return messages, rows.Err()
+}
+
+// titleFor derives a short conversation title from its first user message.
+func titleFor(content string) string {
+	return title(content)
+}
@@ -1,22 +1,27 @@
 package lambdachat
 
 import (
-	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
 	"sync"
-
-	openai "github.com/sashabaranov/go-openai"
-
+	"time"
+
+	"github.com/lambda/lambdachat-slackbot/internal/agent"
+	"github.com/lambda/lambdachat-slackbot/internal/agent/toolbox"
+	"github.com/lambda/lambdachat-slackbot/internal/config"
+	"github.com/lambda/lambdachat-slackbot/internal/convstore"
+	"github.com/lambda/lambdachat-slackbot/internal/provider"
+	"github.com/lambda/lambdachat-slackbot/internal/template"
 	"github.com/sirupsen/logrus"
 )
 
 const (
 	// LambdaChatURL is the OpenAPI endpoint for Lambda Chat
-	LambdaChatURL string = "https://api.lambdalabs.com/v1"
+	LambdaChatURL string = provider.LambdaChatURL
 
 	// DefaultModel is the default model to use if none is specified
 	DefaultModel string = "deepseek-llama3.3-70b"
@@ -87,45 +92,316 @@ type LambdaChatter interface {
 	// ChatStream sends a message to the Lambda Chat API and streams the response
 	ChatStream(userID, message string, writer io.Writer) error
 
-	// Reset clears the conversation history for a user
+	// Continue re-prompts the model to keep generating from the user's
+	// current conversation leaf, streaming the additional tokens to writer,
+	// without adding a new user message. It's the common "keep going" case
+	// for a reply that was cut short by max_tokens, a network drop, or a
+	// user-initiated stop.
+	Continue(userID string, writer io.Writer) error
+
+	// ChatWithTools sends a message along with a set of tools the model may
+	// call. Unlike Chat/ChatStream, it never executes tool calls itself
+	// (unless the client was constructed with AutoRunTools): the returned
+	// Reply surfaces any requested tool_calls so the caller can confirm
+	// before running them.
+	ChatWithTools(userID, message string, tools []toolbox.ToolSpec) (Reply, error)
+
+	// ChatStreamWithTools is the streaming counterpart of ChatWithTools.
+	// Content tokens are written to writer as they arrive; any tool calls
+	// requested by the model are returned in the final Reply instead of
+	// being streamed.
+	ChatStreamWithTools(userID, message string, tools []toolbox.ToolSpec, writer io.Writer) (Reply, error)
+
+	// Reset starts a fresh conversation branch for a user. The previous
+	// conversation is not discarded: it remains available via
+	// ListConversations/SwitchConversation.
 	Reset(userID string) error
 
-	// SetPersona changes the persona for a specific user
+	// SetPersona changes the persona for a specific user. If personaName
+	// matches a registered Agent's name, the agent is bound to the user
+	// instead: its system prompt becomes the persona and its tools become
+	// available via ChatWithTools/ChatStreamWithTools.
 	SetPersona(userID, personaName string) (string, error)
 
+	// SetSystemPrompt starts a fresh conversation for userID seeded with an
+	// arbitrary system prompt, bypassing the registered personas entirely.
+	// It clears any persona or agent previously bound to userID.
+	SetSystemPrompt(userID, prompt string) error
+
 	// SetModel changes the model for a specific user
 	SetModel(userID, modelName string) (string, error)
 
+	// CurrentModel returns the model ID in effect for userID, or the
+	// default model if they haven't chosen one. Intended for callers that
+	// want a label (e.g. tracing) rather than to drive a chat turn.
+	CurrentModel(userID string) string
+
+	// CurrentPersona returns the display name of the persona or bound
+	// agent in effect for userID, or "default" if they haven't chosen
+	// one. Intended for callers that want a label (e.g. tracing).
+	CurrentPersona(userID string) string
+
+	// Reload re-reads the config directory passed to New and refreshes the
+	// available personas and models, so operators can add or change them
+	// without restarting. A no-op beyond re-fetching models if no config
+	// directory was configured.
+	Reload() error
+
 	// GetAvailablePersonas returns a list of available personas
 	GetAvailablePersonas() []string
 
 	// GetAvailableModels returns a list of available models
 	GetAvailableModels() []string
+
+	// ModelInfo returns structured metadata for every available model, for
+	// callers that want to render more than a bullet list (e.g. Block Kit).
+	ModelInfo() []ModelSummary
+
+	// PersonaInfo returns structured metadata for every available persona,
+	// for callers that want to render more than a bullet list (e.g. Block
+	// Kit).
+	PersonaInfo() []PersonaSummary
+
+	// AgentInfo returns structured metadata for every registered agent.
+	// Bind one to a user session via SetPersona, the same as a persona.
+	AgentInfo() []AgentSummary
+
+	// HealthInfo returns every available model's current fallback-routing
+	// health, for rendering via `/health` or the WebUI's /api/health.
+	HealthInfo() []ModelHealthSummary
+
+	// NewConversation starts a fresh, empty conversation for userID and
+	// makes it the active one, returning its ID.
+	NewConversation(userID string) (string, error)
+
+	// SwitchConversation makes convoID the active conversation for userID.
+	SwitchConversation(userID, convoID string) error
+
+	// ListConversations returns metadata for every conversation belonging
+	// to userID, most recently updated first.
+	ListConversations(userID string) ([]convstore.ConversationMeta, error)
+
+	// DeleteConversation permanently removes a conversation.
+	DeleteConversation(userID, convoID string) error
+
+	// RenameConversation sets a conversation's display title, overriding
+	// its auto-generated one.
+	RenameConversation(userID, convoID, title string) error
+
+	// Fork branches a new conversation from messageID within convoID and
+	// switches userID to it, returning the new conversation's ID.
+	Fork(userID, convoID, messageID string) (string, error)
+
+	// Edit rewrites messageID's content, discards any messages that came
+	// after it on that branch, and re-prompts the model from that point,
+	// returning the new assistant reply.
+	Edit(userID, convoID, messageID, newContent string) (string, error)
+
+	// Regenerate discards the active conversation's last assistant reply and
+	// re-prompts the model with the same last user message, returning the
+	// new reply. It returns an error if the conversation has no user message
+	// yet to regenerate a reply for.
+	Regenerate(userID string) (string, error)
+
+	// Close cancels the client's internal context, aborting any in-flight
+	// request (e.g. a streaming Chat call) immediately instead of waiting
+	// for it to finish. A client is not usable after Close; it exists so a
+	// caller like a CLI's Ctrl-C handler can abort a partial response.
+	Close()
+}
+
+// Reply is the result of a tool-call-aware chat turn. ToolCalls is non-empty
+// only when the model asked to invoke tools and the client did not run them
+// automatically.
+type Reply struct {
+	Content   string
+	ToolCalls []provider.ToolCall
+}
+
+// ModelSummary is a model's selectable name plus the metadata a client would
+// want to render alongside it (e.g. a Block Kit message), without exposing
+// the internal modelInfo type.
+type ModelSummary struct {
+	Name          string // value to pass to SetModel
+	Description   string // human-readable display name
+	ContextLength int    // maximum context window in tokens, or 0 if unknown
+}
+
+// PersonaSummary is a persona's selectable name plus the metadata a client
+// would want to render alongside it, without exposing the internal
+// personaInfo type.
+type PersonaSummary struct {
+	Name        string // value to pass to SetPersona
+	Description string // human-readable summary
+}
+
+// AgentSummary is an agent's selectable name plus the tools it exposes, for
+// a client that wants to list available agents.
+type AgentSummary struct {
+	Name      string   // value to pass to SetPersona to bind this agent
+	ToolNames []string // names of the tools this agent can call
+}
+
+// ModelHealthSummary is one model's current fallback-routing health, for a
+// client that wants to render `/health` or the WebUI's /api/health.
+type ModelHealthSummary struct {
+	Model               string        // model ID
+	Healthy             bool          // false while in cooldown
+	ConsecutiveFailures int           // consecutive failures since the last success
+	CooldownUntil       time.Time     // zero if not in cooldown
+	LastError           string        // most recent error, or "" if none recorded
+	LastLatency         time.Duration // latency of the most recent attempt
+	SuccessRate         float64       // successes / total attempts, or 0 if none recorded
 }
 
 // modelInfo represents information about a model
 type modelInfo struct {
-	ID          string   // API identifier for the model
-	DisplayName string   // Human-readable name for the model
-	Aliases     []string // Alternative names for the model
-	AutoPersona string   // Optional persona to set when selecting this model
+	ID            string                // API identifier for the model
+	DisplayName   string                // Human-readable name for the model
+	Aliases       []string              // Alternative names for the model
+	AutoPersona   string                // Optional persona name to set when selecting this model
+	AutoAgent     string                // Optional agent name to bind when selecting this model
+	Provider      string                // Name of the ChatCompletionProvider that serves this model
+	Template      template.ChatTemplate // Chat template used to split reasoning from visible output
+	Temperature   *float64              // Optional default sampling temperature
+	TopP          *float64              // Optional default nucleus-sampling threshold
+	MaxTokens     *int                  // Optional default max tokens to generate
+	ContextLength int                   // Maximum context window in tokens, or 0 if unknown
+	Fallbacks     []string              // Model IDs to retry, in order, when this model is unhealthy
+}
+
+// Cooldown bounds for modelHealth: the first failure cools a model down for
+// healthBaseCooldown, doubling on every consecutive failure up to
+// healthMaxCooldown.
+const (
+	healthBaseCooldown = 30 * time.Second
+	healthMaxCooldown  = 10 * time.Minute
+)
+
+// modelHealth tracks one model's recent fallback-routing health: consecutive
+// failures, the most recent error and latency, and success and attempt
+// counts accumulated since startup (not over a rolling window).
+type modelHealth struct {
+	consecutiveFailures int
+	cooldownUntil       time.Time
+	lastError           string
+	lastLatency         time.Duration
+	successCount        int
+	totalCount          int
+}
+
+// personaInfo represents a selectable persona.
+type personaInfo struct {
+	Name         string   // Name used for SetPersona selection
+	Aliases      []string // Alternative names for the persona
+	Description  string   // Human-readable summary shown in GetAvailablePersonas
+	SystemPrompt string   // Injected as the conversation's system message
+}
+
+// bundledPersonas returns the built-in personas used when no config
+// directory is set.
+func bundledPersonas() []personaInfo {
+	return []personaInfo{
+		{Name: "bender", Aliases: []string{"futurama"}, Description: "Bender from Futurama", SystemPrompt: PersonaBender},
+		{Name: "assistant", Aliases: []string{"helpful"}, Description: "Helpful Assistant", SystemPrompt: PersonaHelpfulAssistant},
+		{Name: "writer", Aliases: []string{"writing"}, Description: "Writing Assistant", SystemPrompt: PersonaWritingAssistant},
+		{Name: "coder", Aliases: []string{"coding", "programmer"}, Description: "Coding Assistant", SystemPrompt: PersonaCodingAssistant},
+	}
+}
+
+// personasFromConfig converts config-defined personas into personaInfo,
+// defaulting Description to Name when the config doesn't set one.
+func personasFromConfig(configured []config.PersonaConfig) []personaInfo {
+	personas := make([]personaInfo, 0, len(configured))
+	for _, pc := range configured {
+		description := pc.Description
+		if description == "" {
+			description = pc.Name
+		}
+		personas = append(personas, personaInfo{
+			Name:         pc.Name,
+			Aliases:      pc.Aliases,
+			Description:  description,
+			SystemPrompt: pc.SystemPrompt,
+		})
+	}
+	return personas
 }
 
 type lambdaChat struct {
-	client *openai.Client
+	// providers are the registered chat-completion backends, keyed by name.
+	providers map[string]provider.ChatCompletionProvider
 
-	// Map of user IDs to conversation histories
-	conversations map[string][]openai.ChatCompletionMessage
+	// defaultProvider is used for models that don't name a specific one.
+	defaultProvider string
+
+	// store persists conversations as per-user message trees.
+	store convstore.ConversationStore
+
+	// Map of user IDs to their active conversation ID
+	userConvo map[string]string
 
 	// Map of user IDs to their preferred personas
 	userPersonas map[string]string
 
+	// Map of user IDs to the display name of their preferred persona or
+	// bound agent, for callers (e.g. tracing) that want a label without
+	// the full system prompt. Absent for users on the default persona.
+	userPersonaNames map[string]string
+
 	// Map of user IDs to their preferred models
 	userModels map[string]string
 
+	// Map of user IDs to the Agent bound to their session, if any
+	userAgents map[string]*agent.Agent
+
+	// prefsLoaded tracks which users' saved preferences (model, persona)
+	// have already been loaded from store into userModels/userPersonas
+	// this process, so each user is loaded at most once, lazily, on their
+	// first interaction rather than all at startup.
+	prefsLoaded map[string]bool
+
+	// Agents registered at construction time, keyed by lowercase name
+	agents map[string]*agent.Agent
+
+	// agentAllowlist, if non-empty, restricts which userIDs may bind an
+	// agent (directly via SetPersona, or automatically via SetModel's
+	// AutoAgent) to the userIDs it contains; anyone else's attempt is
+	// rejected. An agent's tools execute against the live
+	// filesystem/shell with no per-call confirmation once bound, so an
+	// operator exposing one on a shared transport (e.g. Slack) should set
+	// this. Empty means unrestricted, same as webUIToken's default.
+	agentAllowlist map[string]bool
+
+	// AutoRunTools makes ChatWithTools/ChatStreamWithTools execute
+	// requested tool calls immediately and loop until the model returns a
+	// plain message, rather than surfacing ToolCalls to the caller.
+	autoRunTools bool
+
+	// toolObserver, if set, is notified of every tool call an agent
+	// executes, so a caller (e.g. the Slack bot's WebUI) can surface them
+	// without lambdachat depending on that caller's package.
+	toolObserver func(userID, toolName, result string)
+
 	// Available models information
 	availableModels []modelInfo
 
+	// modelHealth tracks per-model health for fallback routing, keyed by
+	// model ID. Guarded by healthMu rather than mu since it's written on
+	// every chat completion, independent of the user/persona/model maps.
+	modelHealth map[string]*modelHealth
+	healthMu    sync.Mutex
+
+	// Available personas, loaded from configDir or bundledPersonas
+	availablePersonas []personaInfo
+
+	// configDir is the directory of YAML files defining personas and
+	// models, or empty to use the bundled built-in defaults.
+	configDir string
+
+	// cfg is the config most recently loaded from configDir.
+	cfg *config.Config
+
 	// Mutex to protect the maps
 	mu sync.RWMutex
 
@@ -142,46 +418,71 @@ type lambdaChat struct {
 	token string
 }
 
-type LoggingTransport struct {
-	Transport http.RoundTripper
-	l         *logrus.Entry
-}
+// fetchModels retrieves available models, preferring a configured set of
+// models from configDir if one was loaded, and otherwise falling back to
+// the default provider's API (if it supports listing them) or a bundled
+// default set.
+func (lc *lambdaChat) fetchModels() error {
+	lc.mu.RLock()
+	cfg := lc.cfg
+	lc.mu.RUnlock()
 
-func (t *LoggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	t.l.Infof("Method %q Request URL %q", req.Method, req.URL.String())
-	return t.Transport.RoundTrip(req)
-}
+	if cfg != nil && len(cfg.Models) > 0 {
+		models, err := modelsFromConfig(cfg.Models)
+		if err != nil {
+			return fmt.Errorf("building models from config: %w", err)
+		}
+		lc.l.Infof("Using %d models from config", len(models))
+		lc.availableModels = models
+		return nil
+	}
 
-// fetchModels retrieves available models from the API
-func (lc *lambdaChat) fetchModels() error {
 	// Initialize with a default set of known models in case API call fails
 	defaultModels := []modelInfo{
 		{
-			ID:          "deepseek-llama3.3-70b",
-			DisplayName: "DeepSeek Llama 3.3 70B",
-			Aliases:     []string{"deepseek", "deepseek-llama", "llama3.3"},
+			ID:            "deepseek-llama3.3-70b",
+			DisplayName:   "DeepSeek Llama 3.3 70B",
+			Aliases:       []string{"deepseek", "deepseek-llama", "llama3.3"},
+			Provider:      provider.NameLambda,
+			Template:      template.ForModel("deepseek-llama3.3-70b"),
+			ContextLength: 128000,
 		},
 		{
-			ID:          "hermes-405b",
-			DisplayName: "Hermes 405B",
-			Aliases:     []string{"hermes405b", "hermes405", "405b"},
+			ID:            "hermes-405b",
+			DisplayName:   "Hermes 405B",
+			Aliases:       []string{"hermes405b", "hermes405", "405b"},
+			Provider:      provider.NameLambda,
+			Template:      template.ForModel("hermes-405b"),
+			ContextLength: 128000,
 		},
 		{
-			ID:          "hermes-70b",
-			DisplayName: "Hermes 70B",
-			Aliases:     []string{"hermes70b", "hermes70", "70b"},
+			ID:            "hermes-70b",
+			DisplayName:   "Hermes 70B",
+			Aliases:       []string{"hermes70b", "hermes70", "70b"},
+			Provider:      provider.NameLambda,
+			Template:      template.ForModel("hermes-70b"),
+			ContextLength: 128000,
 		},
 		{
-			ID:          "qwen-25-coder",
-			DisplayName: "Qwen 25 Coder",
-			Aliases:     []string{"qwen", "qwen25", "coder"},
-			AutoPersona: PersonaCodingAssistant,
+			ID:            "qwen-25-coder",
+			DisplayName:   "Qwen 25 Coder",
+			Aliases:       []string{"qwen", "qwen25", "coder"},
+			AutoAgent:     "coder",
+			Provider:      provider.NameLambda,
+			Template:      template.ForModel("qwen-25-coder"),
+			ContextLength: 32768,
 		},
 	}
 
-	// Try to get models from API
+	lister, ok := lc.providers[lc.defaultProvider].(provider.ModelLister)
+	if !ok {
+		lc.l.Info("Default provider does not support listing models; using defaults.")
+		lc.availableModels = defaultModels
+		return nil
+	}
+
 	lc.l.Info("Fetching available models from API...")
-	modelList, err := lc.client.ListModels(lc.ctx)
+	modelIDs, err := lister.ListModels(lc.ctx)
 	if err != nil {
 		lc.l.Warnf("Failed to fetch models from API: %v. Using default models.", err)
 		lc.availableModels = defaultModels
@@ -190,10 +491,10 @@ func (lc *lambdaChat) fetchModels() error {
 
 	// Convert API models to our internal format
 	apiModels := []modelInfo{}
-	for _, model := range modelList.Models {
+	for _, id := range modelIDs {
 		// Simple display name conversion - remove organization prefix if present
-		displayName := model.ID
-		if parts := strings.Split(model.ID, "/"); len(parts) > 1 {
+		displayName := id
+		if parts := strings.Split(id, "/"); len(parts) > 1 {
 			displayName = parts[len(parts)-1]
 		}
 		// Convert kebab-case to title case
@@ -211,7 +512,7 @@ func (lc *lambdaChat) fetchModels() error {
 
 		// Generate some reasonable aliases
 		aliases := []string{}
-		parts := strings.Split(model.ID, "-")
+		parts := strings.Split(id, "-")
 		if len(parts) > 0 {
 			aliases = append(aliases, parts[0]) // First part
 			if len(parts) > 1 {
@@ -222,9 +523,11 @@ func (lc *lambdaChat) fetchModels() error {
 
 		// Add complete model info
 		apiModels = append(apiModels, modelInfo{
-			ID:          model.ID,
+			ID:          id,
 			DisplayName: displayName,
 			Aliases:     aliases,
+			Provider:    lc.defaultProvider,
+			Template:    template.ForModel(id),
 		})
 	}
 
@@ -233,11 +536,11 @@ func (lc *lambdaChat) fetchModels() error {
 		lc.l.Infof("Found %d models from API", len(apiModels))
 		lc.availableModels = apiModels
 
-		// Special handling for the coding model - maintain its auto persona
+		// Special handling for the coding model - bind the Coder agent
 		for i, model := range lc.availableModels {
 			if strings.Contains(strings.ToLower(model.ID), "coder") ||
 				strings.Contains(strings.ToLower(model.ID), "code") {
-				lc.availableModels[i].AutoPersona = PersonaCodingAssistant
+				lc.availableModels[i].AutoAgent = "coder"
 			}
 		}
 	} else {
@@ -248,8 +551,73 @@ func (lc *lambdaChat) fetchModels() error {
 	return nil
 }
 
-// New creates a new LambdaChatter instance
-func New(l *logrus.Entry, url, token, model, persona string) (LambdaChatter, error) {
+// modelsFromConfig converts config-defined models into modelInfo, resolving
+// each one's chat template by name, by compiling it as raw Jinja source, or
+// by auto-detecting one from its ID.
+func modelsFromConfig(configured []config.ModelConfig) ([]modelInfo, error) {
+	models := make([]modelInfo, 0, len(configured))
+	for _, mc := range configured {
+		tpl, err := resolveModelTemplate(mc)
+		if err != nil {
+			return nil, fmt.Errorf("model %s: %w", mc.ID, err)
+		}
+
+		displayName := mc.DisplayName
+		if displayName == "" {
+			displayName = mc.ID
+		}
+
+		models = append(models, modelInfo{
+			ID:            mc.ID,
+			DisplayName:   displayName,
+			Aliases:       mc.Aliases,
+			AutoPersona:   mc.AutoPersona,
+			AutoAgent:     mc.Agent,
+			Provider:      mc.Provider,
+			Template:      tpl,
+			Temperature:   mc.Parameters.Temperature,
+			TopP:          mc.Parameters.TopP,
+			MaxTokens:     mc.Parameters.MaxTokens,
+			ContextLength: mc.ContextLength,
+			Fallbacks:     mc.Fallbacks,
+		})
+	}
+	return models, nil
+}
+
+// resolveModelTemplate picks mc's chat template: a built-in named by
+// mc.Template, raw Jinja source compiled from mc.Template, or (if mc.Template
+// is empty) one auto-detected from mc.ID.
+func resolveModelTemplate(mc config.ModelConfig) (template.ChatTemplate, error) {
+	if mc.Template == "" {
+		return template.ForModel(mc.ID), nil
+	}
+	if tpl, ok := template.Lookup(mc.Template); ok {
+		return tpl, nil
+	}
+	return template.Compile(mc.Template, mc.Reasoning)
+}
+
+// New creates a new LambdaChatter instance. autoRunTools, when true, makes
+// ChatWithTools/ChatStreamWithTools execute tool calls immediately instead of
+// returning them to the caller for confirmation; Chat/ChatStream always run
+// tool calls for an agent-bound user regardless of autoRunTools, since they
+// never expose a Reply.ToolCalls to the caller. agents are registered by
+// name and can be bound to a user session via SetPersona; agentAllowlist, if
+// non-empty, restricts binding to the userIDs it names (see the field doc on
+// lambdaChat.agentAllowlist) — pass nil for unrestricted, e.g. from the
+// single-user CLI. toolObserver, if non-nil, is called with (userID,
+// toolName, result) after every tool call an agent executes; pass nil if
+// the caller has no use for this. If store is nil, conversations are kept
+// in memory only. The default provider is chosen by inspecting url (see
+// provider.DetectFromURL); extraProviders are registered alongside it,
+// keyed by their Name(), so SetModel can route to them via
+// modelInfo.Provider. configDir, if non-empty, names a directory of YAML
+// files defining personas and models (see the config package); an empty
+// configDir falls back to the bundled built-in personas and models. headers,
+// if non-empty, are added to every request the default provider makes (e.g.
+// for a reverse proxy in front of an OpenAI-compatible endpoint).
+func New(l *logrus.Entry, url, token string, headers map[string]string, model, persona string, autoRunTools bool, store convstore.ConversationStore, agents []*agent.Agent, agentAllowlist []string, toolObserver func(userID, toolName, result string), configDir string, extraProviders ...provider.ChatCompletionProvider) (LambdaChatter, error) {
 	if url == "" {
 		url = LambdaChatURL
 	}
@@ -259,30 +627,59 @@ func New(l *logrus.Entry, url, token, model, persona string) (LambdaChatter, err
 	if model == "" {
 		model = DefaultModel
 	}
+	if store == nil {
+		store = convstore.NewMemory()
+	}
+
+	agentsByName := make(map[string]*agent.Agent, len(agents))
+	for _, ag := range agents {
+		agentsByName[strings.ToLower(ag.Name)] = ag
+	}
+
+	allowlist := make(map[string]bool, len(agentAllowlist))
+	for _, userID := range agentAllowlist {
+		allowlist[userID] = true
+	}
 
-	// Make the client to use Lambda Chat
-	cfg := openai.DefaultConfig(token)
-	cfg.BaseURL = url
-	cfg.HTTPClient = &http.Client{
-		Transport: &LoggingTransport{http.DefaultTransport, l},
+	defaultProviderName := provider.DetectFromURL(url)
+	providers := map[string]provider.ChatCompletionProvider{
+		defaultProviderName: newDefaultProvider(defaultProviderName, l, url, token, headers),
+	}
+	for _, p := range extraProviders {
+		providers[p.Name()] = p
 	}
 
-	client := openai.NewClientWithConfig(cfg)
 	ctx, done := context.WithCancel(context.Background())
 
 	// Create the lambdaChat instance
 	lc := &lambdaChat{
-		client:         client,
-		conversations:  make(map[string][]openai.ChatCompletionMessage),
-		userPersonas:   make(map[string]string),
-		userModels:     make(map[string]string),
-		model:          model,
-		defaultPersona: persona,
-		l:              l,
-		url:            url,
-		token:          token,
-		ctx:            ctx,
-		done:           done,
+		providers:        providers,
+		defaultProvider:  defaultProviderName,
+		store:            store,
+		userConvo:        make(map[string]string),
+		userPersonas:     make(map[string]string),
+		userPersonaNames: make(map[string]string),
+		userModels:       make(map[string]string),
+		userAgents:       make(map[string]*agent.Agent),
+		prefsLoaded:      make(map[string]bool),
+		agents:           agentsByName,
+		agentAllowlist:   allowlist,
+		modelHealth:      make(map[string]*modelHealth),
+		autoRunTools:     autoRunTools,
+		toolObserver:     toolObserver,
+		model:            model,
+		defaultPersona:   persona,
+		configDir:        configDir,
+		l:                l,
+		url:              url,
+		token:            token,
+		ctx:              ctx,
+		done:             done,
+	}
+
+	if err := lc.loadConfig(); err != nil {
+		l.Warnf("Error loading config: %v", err)
+		// Continue anyway as we fall back to bundled personas
 	}
 
 	// Fetch available models
@@ -294,8 +691,103 @@ func New(l *logrus.Entry, url, token, model, persona string) (LambdaChatter, err
 	return lc, nil
 }
 
+// loadConfig (re)reads lc.configDir and refreshes the available personas,
+// falling back to bundledPersonas if the directory is empty or defines
+// none. It's called by New and Reload.
+func (lc *lambdaChat) loadConfig() error {
+	cfg, err := config.Load(lc.configDir)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	personas := personasFromConfig(cfg.Personas)
+	if len(personas) == 0 {
+		personas = bundledPersonas()
+	}
+
+	lc.mu.Lock()
+	lc.cfg = cfg
+	lc.availablePersonas = personas
+	lc.mu.Unlock()
+
+	return nil
+}
+
+// Reload re-reads the config directory and refreshes available personas and
+// models, so operators can add or change them without restarting.
+func (lc *lambdaChat) Reload() error {
+	if err := lc.loadConfig(); err != nil {
+		return err
+	}
+	return lc.fetchModels()
+}
+
+// newDefaultProvider builds the provider backing url, based on the name
+// DetectFromURL chose for it.
+func newDefaultProvider(name string, l *logrus.Entry, url, token string, headers map[string]string) provider.ChatCompletionProvider {
+	switch name {
+	case provider.NameOllama:
+		return provider.NewOllama(url, headers)
+	case provider.NameAnthropic:
+		return provider.NewAnthropic(url, token, headers)
+	case provider.NameGoogle:
+		return provider.NewGoogle(url, token, headers)
+	default:
+		return provider.NewLambda(l, url, token, headers)
+	}
+}
+
+// ensurePreferencesLoaded loads userID's saved model/persona preferences
+// from store into userModels/userPersonas the first time it's seen in this
+// process, so they survive a restart without every user paying a store
+// round trip on every turn.
+func (lc *lambdaChat) ensurePreferencesLoaded(userID string) {
+	lc.mu.Lock()
+	if lc.prefsLoaded[userID] {
+		lc.mu.Unlock()
+		return
+	}
+	lc.prefsLoaded[userID] = true
+	lc.mu.Unlock()
+
+	prefs, err := lc.store.LoadPreferences(userID)
+	if err != nil {
+		lc.l.Warnf("Error loading preferences for %s: %v", userID, err)
+		return
+	}
+
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	if modelID, ok := prefs[prefKeyModel]; ok {
+		lc.userModels[userID] = modelID
+	}
+	if personaName, ok := prefs[prefKeyPersona]; ok {
+		if persona, err := lc.findPersona(personaName); err == nil {
+			lc.userPersonas[userID] = persona.SystemPrompt
+			lc.userPersonaNames[userID] = persona.Name
+		}
+	}
+}
+
+// savePreference persists userID's preference for key, logging (but not
+// failing the caller) if the store can't be written.
+func (lc *lambdaChat) savePreference(userID, key, value string) {
+	if err := lc.store.SavePreference(userID, key, value); err != nil {
+		lc.l.Warnf("Error saving %s preference for %s: %v", key, userID, err)
+	}
+}
+
+// prefKeyModel and prefKeyPersona name the preferences saved via
+// SetModel/SetPersona and restored by ensurePreferencesLoaded.
+const (
+	prefKeyModel   = "model"
+	prefKeyPersona = "persona"
+)
+
 // getUserModel returns the model for a specific user, or the default model if not set
 func (lc *lambdaChat) getUserModel(userID string) string {
+	lc.ensurePreferencesLoaded(userID)
+
 	lc.mu.RLock()
 	defer lc.mu.RUnlock()
 
@@ -306,6 +798,139 @@ func (lc *lambdaChat) getUserModel(userID string) string {
 	return model
 }
 
+// providerForModel returns the ChatCompletionProvider that serves modelID,
+// falling back to the default provider if the model is unknown or doesn't
+// name one.
+func (lc *lambdaChat) providerForModel(modelID string) provider.ChatCompletionProvider {
+	if info, err := lc.findModel(modelID); err == nil && info.Provider != "" {
+		if p, ok := lc.providers[info.Provider]; ok {
+			return p
+		}
+	}
+	return lc.providers[lc.defaultProvider]
+}
+
+// templateForModel returns the ChatTemplate associated with modelID,
+// falling back to ChatML if the model is unknown or doesn't name one.
+func (lc *lambdaChat) templateForModel(modelID string) template.ChatTemplate {
+	if info, err := lc.findModel(modelID); err == nil && info.Template != nil {
+		return info.Template
+	}
+	return template.ChatML
+}
+
+// isHealthy reports whether modelID is currently outside its cooldown
+// window. A model with no recorded history is healthy by default.
+func (lc *lambdaChat) isHealthy(modelID string) bool {
+	lc.healthMu.Lock()
+	defer lc.healthMu.Unlock()
+
+	h, ok := lc.modelHealth[modelID]
+	if !ok {
+		return true
+	}
+	return !time.Now().Before(h.cooldownUntil)
+}
+
+// recordFailure marks modelID unhealthy for an exponentially growing
+// cooldown (healthBaseCooldown, doubling per consecutive failure, capped at
+// healthMaxCooldown).
+func (lc *lambdaChat) recordFailure(modelID string, err error, latency time.Duration) {
+	lc.healthMu.Lock()
+	defer lc.healthMu.Unlock()
+
+	h := lc.modelHealth[modelID]
+	if h == nil {
+		h = &modelHealth{}
+		lc.modelHealth[modelID] = h
+	}
+	h.consecutiveFailures++
+	h.lastError = err.Error()
+	h.lastLatency = latency
+	h.totalCount++
+
+	cooldown := healthBaseCooldown << (h.consecutiveFailures - 1)
+	if cooldown <= 0 || cooldown > healthMaxCooldown {
+		cooldown = healthMaxCooldown
+	}
+	h.cooldownUntil = time.Now().Add(cooldown)
+}
+
+// recordSuccess clears modelID's failure streak and cooldown.
+func (lc *lambdaChat) recordSuccess(modelID string, latency time.Duration) {
+	lc.healthMu.Lock()
+	defer lc.healthMu.Unlock()
+
+	h := lc.modelHealth[modelID]
+	if h == nil {
+		h = &modelHealth{}
+		lc.modelHealth[modelID] = h
+	}
+	h.consecutiveFailures = 0
+	h.cooldownUntil = time.Time{}
+	h.lastLatency = latency
+	h.successCount++
+	h.totalCount++
+}
+
+// isRetryable reports whether err represents a transient failure (a 5xx or
+// 429 response) that's worth marking the model unhealthy and falling back
+// for, as opposed to a permanent one (e.g. a malformed request).
+func isRetryable(err error) bool {
+	var se provider.StatusError
+	if !errors.As(err, &se) {
+		// No status available (e.g. a network error): treat as transient.
+		return true
+	}
+	code := se.StatusCode()
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// fallbackChain returns userModel followed by its configured Fallbacks, in
+// the order chatWithFallback should try them.
+func (lc *lambdaChat) fallbackChain(userModel string) []string {
+	chain := []string{userModel}
+	if info, err := lc.findModel(userModel); err == nil {
+		chain = append(chain, info.Fallbacks...)
+	}
+	return chain
+}
+
+// chatCompletionWithFallback tries userModel, then its configured Fallbacks
+// in order, skipping any currently in cooldown, until one answers. It
+// returns the reply along with the model ID that actually answered, so
+// callers can tell the user when that differs from userModel.
+func (lc *lambdaChat) chatCompletionWithFallback(messages []provider.Message, userModel string) (provider.Reply, string, error) {
+	var lastErr error
+	for _, modelID := range lc.fallbackChain(userModel) {
+		if !lc.isHealthy(modelID) {
+			continue
+		}
+
+		start := time.Now()
+		params := lc.paramsForModel(modelID)
+		params.Model = modelID
+		params.Messages = messages
+		reply, err := lc.providerForModel(modelID).CreateChatCompletion(lc.ctx, params)
+		latency := time.Since(start)
+		if err == nil {
+			lc.recordSuccess(modelID, latency)
+			return reply, modelID, nil
+		}
+
+		lastErr = err
+		if !isRetryable(err) {
+			return provider.Reply{}, "", err
+		}
+		lc.recordFailure(modelID, err, latency)
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no healthy model available for %s", userModel)
+	}
+	return provider.Reply{}, "", lastErr
+}
+
 // findModel looks for a model by name or alias
 func (lc *lambdaChat) findModel(modelName string) (*modelInfo, error) {
 	lowerName := strings.ToLower(modelName)
@@ -326,7 +951,44 @@ func (lc *lambdaChat) findModel(modelName string) (*modelInfo, error) {
 	return nil, fmt.Errorf("unknown model: %s", modelName)
 }
 
-// SetModel changes the model for a specific user and optionally updates their persona
+// paramsForModel builds a provider.Request carrying modelID's default
+// generation parameters, if any are configured; an unknown modelID is left
+// with no defaults rather than erroring, since callers already handle an
+// unknown model at the provider-lookup stage.
+func (lc *lambdaChat) paramsForModel(modelID string) provider.Request {
+	info, err := lc.findModel(modelID)
+	if err != nil {
+		return provider.Request{}
+	}
+	return provider.Request{
+		Temperature: info.Temperature,
+		TopP:        info.TopP,
+		MaxTokens:   info.MaxTokens,
+	}
+}
+
+// findPersona looks for a persona by name or alias.
+func (lc *lambdaChat) findPersona(name string) (*personaInfo, error) {
+	lowerName := strings.ToLower(name)
+
+	for _, persona := range lc.availablePersonas {
+		if strings.ToLower(persona.Name) == lowerName {
+			return &persona, nil
+		}
+
+		// Check if any alias matches
+		for _, alias := range persona.Aliases {
+			if strings.ToLower(alias) == lowerName {
+				return &persona, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("unknown persona: %s", name)
+}
+
+// SetModel changes the model for a specific user and optionally binds its
+// auto-agent or auto-persona.
 func (lc *lambdaChat) SetModel(userID, modelName string) (string, error) {
 	// Find the requested model
 	model, err := lc.findModel(modelName)
@@ -334,37 +996,90 @@ func (lc *lambdaChat) SetModel(userID, modelName string) (string, error) {
 		return "", err
 	}
 
+	// If this model should automatically bind an agent, do so in place of
+	// any auto-persona.
+	if model.AutoAgent != "" {
+		if ag, ok := lc.agents[strings.ToLower(model.AutoAgent)]; ok {
+			if !lc.agentBindAllowed(userID) {
+				return "", fmt.Errorf("agent binding is restricted; %s is not on the allowlist for %s", userID, ag.Name)
+			}
+			lc.mu.Lock()
+			lc.userModels[userID] = model.ID
+			lc.userAgents[userID] = ag
+			delete(lc.userPersonas, userID)
+			delete(lc.userPersonaNames, userID)
+			lc.mu.Unlock()
+			lc.savePreference(userID, prefKeyModel, model.ID)
+
+			if err := lc.resetConversationWithSystemPrompt(userID, ag.SystemPrompt); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("*Model changed to %s, and agent automatically set to %s.* Your conversation has been reset.", model.DisplayName, ag.Name), nil
+		}
+		lc.l.Warnf("model %s names unknown auto-agent %q", model.ID, model.AutoAgent)
+	}
+
+	// If this model should automatically change the persona, look it up.
+	var persona *personaInfo
+	if model.AutoPersona != "" {
+		persona, err = lc.findPersona(model.AutoPersona)
+		if err != nil {
+			lc.l.Warnf("model %s names unknown auto-persona %q: %v", model.ID, model.AutoPersona, err)
+		}
+	}
+
 	lc.mu.Lock()
 	// Store the user's preferred model
 	lc.userModels[userID] = model.ID
 
-	// If this model should automatically change the persona, do so
 	var description string
-	if model.AutoPersona != "" {
+	if persona != nil {
 		// Store the user's preferred persona
-		lc.userPersonas[userID] = model.AutoPersona
-
-		// Update the conversation for this user with the new persona
-		lc.conversations[userID] = []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleSystem,
-				Content: model.AutoPersona,
-			},
-		}
-
-		description = ", and persona automatically set to Coding Assistant"
+		lc.userPersonas[userID] = persona.SystemPrompt
+		lc.userPersonaNames[userID] = persona.Name
+		description = fmt.Sprintf(", and persona automatically set to %s", persona.Description)
 	}
 	lc.mu.Unlock()
+	lc.savePreference(userID, prefKeyModel, model.ID)
+	if persona != nil {
+		lc.savePreference(userID, prefKeyPersona, persona.Name)
+	}
 
-	if model.AutoPersona != "" {
+	if persona != nil {
+		if err := lc.resetConversationWithSystemPrompt(userID, persona.SystemPrompt); err != nil {
+			return "", err
+		}
 		return fmt.Sprintf("*Model changed to %s%s.* Your conversation has been reset.", model.DisplayName, description), nil
 	}
 
 	return fmt.Sprintf("*Model changed to %s.*", model.DisplayName), nil
 }
 
+// CurrentModel returns the model ID in effect for userID, or the default
+// model if they haven't chosen one.
+func (lc *lambdaChat) CurrentModel(userID string) string {
+	return lc.getUserModel(userID)
+}
+
+// CurrentPersona returns the display name of the persona or bound agent in
+// effect for userID, or "default" if they haven't chosen one.
+func (lc *lambdaChat) CurrentPersona(userID string) string {
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+
+	if ag, ok := lc.userAgents[userID]; ok {
+		return ag.Name
+	}
+	if name, ok := lc.userPersonaNames[userID]; ok {
+		return name
+	}
+	return "default"
+}
+
 // getUserPersona returns the persona for a specific user, or the default persona if not set
 func (lc *lambdaChat) getUserPersona(userID string) string {
+	lc.ensurePreferencesLoaded(userID)
+
 	lc.mu.RLock()
 	defer lc.mu.RUnlock()
 
@@ -375,264 +1090,616 @@ func (lc *lambdaChat) getUserPersona(userID string) string {
 	return persona
 }
 
-// getConversation returns the conversation history for a user, creating it if it doesn't exist
-func (lc *lambdaChat) getConversation(userID string) []openai.ChatCompletionMessage {
+// ensureConversation returns the user's active conversation ID, creating a
+// new one seeded with their persona if they don't have one yet.
+func (lc *lambdaChat) ensureConversation(userID string) (string, error) {
 	lc.mu.RLock()
-	conversation, exists := lc.conversations[userID]
+	convoID, exists := lc.userConvo[userID]
 	lc.mu.RUnlock()
+	if exists {
+		return convoID, nil
+	}
 
-	if !exists {
-		// Get the user's persona or use the default
-		userPersona := lc.getUserPersona(userID)
+	return lc.newConversationLocked(userID, lc.getUserPersona(userID))
+}
 
-		// Initialize a new conversation with the persona
-		conversation = []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleSystem,
-				Content: userPersona,
-			},
-		}
-		lc.mu.Lock()
-		lc.conversations[userID] = conversation
-		lc.mu.Unlock()
+// newConversationLocked creates a new conversation seeded with systemPrompt,
+// makes it the user's active conversation, and returns its ID.
+func (lc *lambdaChat) newConversationLocked(userID, systemPrompt string) (string, error) {
+	convoID, err := lc.store.NewConversation(userID)
+	if err != nil {
+		return "", fmt.Errorf("creating conversation: %w", err)
+	}
+	if _, err := lc.store.AppendMessage(userID, convoID, "", convstore.Message{
+		Role:    provider.RoleSystem,
+		Content: systemPrompt,
+	}); err != nil {
+		return "", fmt.Errorf("seeding conversation: %w", err)
+	}
+
+	lc.mu.Lock()
+	lc.userConvo[userID] = convoID
+	lc.mu.Unlock()
+
+	return convoID, nil
+}
+
+// resetConversationWithSystemPrompt starts a fresh conversation for userID
+// seeded with systemPrompt, used whenever the persona/agent/model changes.
+func (lc *lambdaChat) resetConversationWithSystemPrompt(userID, systemPrompt string) error {
+	_, err := lc.newConversationLocked(userID, systemPrompt)
+	return err
+}
+
+// getConversation returns the user's active conversation ID and its history
+// as provider-agnostic messages, creating the conversation if it doesn't exist.
+func (lc *lambdaChat) getConversation(userID string) (string, []provider.Message, error) {
+	convoID, err := lc.ensureConversation(userID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	history, err := lc.store.History(userID, convoID)
+	if err != nil {
+		return "", nil, fmt.Errorf("loading conversation history: %w", err)
+	}
+
+	messages := make([]provider.Message, 0, len(history))
+	for _, m := range history {
+		messages = append(messages, provider.Message{
+			Role:    m.Role,
+			Content: m.Content,
+		})
+	}
+	return convoID, messages, nil
+}
+
+// recordTurn appends a user message and the assistant's reply to convoID, as
+// children of its current leaf.
+func (lc *lambdaChat) recordTurn(userID, convoID, model, userContent, assistantContent string) error {
+	leaf, err := lc.store.Leaf(userID, convoID)
+	if err != nil {
+		return fmt.Errorf("finding conversation leaf: %w", err)
 	}
 
-	return conversation
+	userMsg, err := lc.store.AppendMessage(userID, convoID, leaf, convstore.Message{
+		Role:    provider.RoleUser,
+		Content: userContent,
+		Model:   model,
+	})
+	if err != nil {
+		return fmt.Errorf("recording user message: %w", err)
+	}
+
+	if _, err := lc.store.AppendMessage(userID, convoID, userMsg.ID, convstore.Message{
+		Role:    provider.RoleAssistant,
+		Content: assistantContent,
+		Model:   model,
+	}); err != nil {
+		return fmt.Errorf("recording assistant message: %w", err)
+	}
+	return nil
 }
 
-// Chat sends a message to the Lambda Chat API and returns the response
+// maybeGenerateTitle asks the model for a short 4-6 word summary of a
+// conversation's opening exchange and renames convoID to it, replacing the
+// mechanical truncated-message title convstore assigns new conversations by
+// default. Errors are logged, not returned: a missing title is cosmetic,
+// never worth failing the chat turn that triggered it.
+func (lc *lambdaChat) maybeGenerateTitle(userID, convoID, userMessage, assistantReply string) {
+	prompt := fmt.Sprintf(
+		"Summarize the following exchange as a short title of 4 to 6 words. "+
+			"Reply with only the title, no punctuation or quotes.\n\nUser: %s\nAssistant: %s",
+		userMessage, assistantReply,
+	)
+	reply, _, err := lc.chatCompletionWithFallback([]provider.Message{
+		{Role: provider.RoleUser, Content: prompt},
+	}, lc.getUserModel(userID))
+	if err != nil {
+		lc.l.Warnf("Error generating conversation title: %v", err)
+		return
+	}
+
+	title := strings.TrimSpace(strings.Trim(strings.TrimSpace(reply.Content), `"'`))
+	if title == "" {
+		return
+	}
+	if err := lc.store.Rename(userID, convoID, title); err != nil {
+		lc.l.Warnf("Error renaming conversation %s: %v", convoID, err)
+	}
+}
+
+// Chat sends a message to the Lambda Chat API and returns the response. If
+// the conversation's current leaf is already an assistant message (e.g. one
+// truncated by max_tokens, a network drop, or a user-initiated stop), the
+// model is re-prompted with that history as-is and the new tokens are
+// appended onto the existing message instead of starting a new turn.
 func (lc *lambdaChat) Chat(userID, message string) (string, error) {
+	// An agent-bound user always gets the tool-call round-trip, regardless
+	// of autoRunTools: Chat's contract is to return finished text, never a
+	// raw ToolCalls for the caller to run itself.
+	if ag := lc.getUserAgent(userID); ag != nil {
+		reply, err := lc.chatWithToolsLoop(userID, message, ag.Tools, true)
+		if err != nil {
+			return "", err
+		}
+		return reply.Content, nil
+	}
+
 	// Get the conversation history for this user
-	conversation := lc.getConversation(userID)
+	convoID, conversation, err := lc.getConversation(userID)
+	if err != nil {
+		return "", err
+	}
 
 	// Get the user's preferred model
 	userModel := lc.getUserModel(userID)
 
-	// Create a new request with the user's message
-	resp, err := lc.client.CreateChatCompletion(
-		lc.ctx,
-		openai.ChatCompletionRequest{
-			Model: userModel,
-			Messages: append(
-				conversation,
-				openai.ChatCompletionMessage{
-					Role:    openai.ChatMessageRoleUser,
-					Content: message,
-				},
-			),
-		},
-	)
+	continuing := isPartialAssistantReply(conversation)
+	messages := conversation
+	if !continuing {
+		messages = append(messages, provider.Message{
+			Role:    provider.RoleUser,
+			Content: message,
+		})
+	}
 
+	// Create a new request with the user's message, transparently falling
+	// back to another model if userModel is unhealthy or errors.
+	reply, modelUsed, err := lc.chatCompletionWithFallback(messages, userModel)
 	if err != nil {
 		return "", fmt.Errorf("chat completion failed: %w", err)
 	}
 
-	if len(resp.Choices) == 0 {
-		return "", fmt.Errorf("no choices returned")
+	msg, _ := lc.templateForModel(modelUsed).ParseThinking(reply.Content)
+	if modelUsed != userModel {
+		msg = fmt.Sprintf("_(note: %s was unavailable; answered by %s)_\n\n%s", userModel, modelUsed, msg)
 	}
 
-	// Process the response
-	msg := ""
-	scanner := bufio.NewScanner(strings.NewReader(resp.Choices[0].Message.Content))
-	scanner.Split(bufio.ScanLines)
-	start := false
-	for scanner.Scan() {
-		if scanner.Err() != nil {
-			break
-		}
-		if scanner.Text() == "</think>" {
-			start = true
-			continue
-		}
-		if start {
-			msg += scanner.Text() + "\n"
+	if continuing {
+		if err := lc.extendTurn(userID, convoID, msg); err != nil {
+			return "", err
 		}
+		return msg, nil
 	}
 
 	// Add the user's message and the assistant's response to the conversation history
-	lc.mu.Lock()
-	lc.conversations[userID] = append(
-		lc.conversations[userID],
-		openai.ChatCompletionMessage{
-			Role:    openai.ChatMessageRoleUser,
-			Content: message,
-		},
-		openai.ChatCompletionMessage{
-			Role:    openai.ChatMessageRoleAssistant,
-			Content: msg,
-		},
-	)
-	lc.mu.Unlock()
+	if err := lc.recordTurn(userID, convoID, modelUsed, message, msg); err != nil {
+		return "", err
+	}
+	if len(conversation) == 1 {
+		lc.maybeGenerateTitle(userID, convoID, message, msg)
+	}
 
 	return msg, nil
 }
 
-// ChatStream sends a message to the Lambda Chat API and streams the response
+// ChatStream sends a message to the Lambda Chat API and streams the
+// response. As with Chat, if the conversation's current leaf is already an
+// assistant message, it's continued in place rather than starting a new turn.
 func (lc *lambdaChat) ChatStream(userID, message string, writer io.Writer) error {
-	conversation := lc.getConversation(userID)
-	userModel := lc.getUserModel(userID)
-	stream, err := lc.client.CreateChatCompletionStream(
-		lc.ctx,
-		openai.ChatCompletionRequest{
-			Model: userModel,
-			Messages: append(
-				conversation,
-				openai.ChatCompletionMessage{
-					Role:    openai.ChatMessageRoleUser,
-					Content: message,
-				},
-			),
-			Stream: true,
-		},
-	)
+	// An agent-bound user always gets the tool-call round-trip; see Chat.
+	if ag := lc.getUserAgent(userID); ag != nil {
+		_, err := lc.chatStreamWithToolsLoop(userID, message, ag.Tools, writer, true)
+		return err
+	}
+
+	convoID, conversation, err := lc.getConversation(userID)
 	if err != nil {
-		return fmt.Errorf("chat completion stream failed: %w", err)
+		return err
 	}
-	defer stream.Close()
+	userModel := lc.getUserModel(userID)
 
-	// Process the streaming response
-	var fullResponse strings.Builder
-	var inThinkingBlock bool
-	var responseStarted bool
+	continuing := isPartialAssistantReply(conversation)
+	messages := conversation
+	if !continuing {
+		messages = append(messages, provider.Message{
+			Role:    provider.RoleUser,
+			Content: message,
+		})
+	}
 
-	for {
-		response, err := stream.Recv()
-		if err != nil {
-			if err == io.EOF {
-				break
+	// Try userModel, then its configured Fallbacks in order, skipping any
+	// currently in cooldown, same as Chat's chatCompletionWithFallback. A
+	// switch is noted directly in the stream so the user sees which model
+	// ultimately answered.
+	var lastErr error
+	for _, modelID := range lc.fallbackChain(userModel) {
+		if !lc.isHealthy(modelID) {
+			continue
+		}
+		if modelID != userModel {
+			if _, err := fmt.Fprintf(writer, "_(note: %s was unavailable; answering with %s)_\n\n", userModel, modelID); err != nil {
+				return err
 			}
-			return fmt.Errorf("stream receive error: %w", err)
 		}
 
-		if len(response.Choices) == 0 {
+		splitter := newTemplateStreamWriter(lc.templateForModel(modelID), writer)
+		start := time.Now()
+		params := lc.paramsForModel(modelID)
+		params.Model = modelID
+		params.Messages = messages
+		_, err := lc.providerForModel(modelID).CreateChatCompletionStream(
+			lc.ctx,
+			params,
+			splitter,
+		)
+		latency := time.Since(start)
+		if err != nil {
+			lc.recordFailure(modelID, err, latency)
+
+			// Once any visible content has reached the caller's writer,
+			// silently switching to another model would concatenate that
+			// partial reply with a second, unrelated one instead of
+			// cleanly falling back, so surface the failure instead of
+			// retrying. Falling back is only safe while nothing has been
+			// shown yet, same as the non-streaming Chat's fallback.
+			if splitter.visible() != "" {
+				if _, werr := fmt.Fprintf(writer, "\n\n_(response interrupted: %v)_", err); werr != nil {
+					return werr
+				}
+				return fmt.Errorf("chat completion stream failed: %w", err)
+			}
+			if !isRetryable(err) {
+				return fmt.Errorf("chat completion stream failed: %w", err)
+			}
+			lastErr = err
 			continue
 		}
+		lc.recordSuccess(modelID, latency)
 
-		content := response.Choices[0].Delta.Content
-		if content == "" {
-			continue
+		if err := splitter.flush(); err != nil {
+			return err
+		}
+		if continuing {
+			return lc.extendTurn(userID, convoID, splitter.visible())
 		}
+		// Add the user's message and the assistant's response to the conversation history
+		if err := lc.recordTurn(userID, convoID, modelID, message, splitter.visible()); err != nil {
+			return err
+		}
+		if len(conversation) == 1 {
+			lc.maybeGenerateTitle(userID, convoID, message, splitter.visible())
+		}
+		return nil
+	}
 
-		// Check for thinking block markers
-		if strings.Contains(content, "<think>") {
-			inThinkingBlock = true
-			continue
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no healthy model available for %s", userModel)
+	}
+	return fmt.Errorf("chat completion stream failed: %w", lastErr)
+}
+
+// Continue re-prompts the model to keep generating from the user's current
+// conversation leaf, without adding a new user message.
+func (lc *lambdaChat) Continue(userID string, writer io.Writer) error {
+	_, conversation, err := lc.getConversation(userID)
+	if err != nil {
+		return err
+	}
+	if !isPartialAssistantReply(conversation) {
+		return fmt.Errorf("lambdachat: nothing to continue: the last message isn't from the assistant")
+	}
+	return lc.ChatStream(userID, "", writer)
+}
+
+// isPartialAssistantReply reports whether conversation's last message is
+// from the assistant, meaning the next turn should continue it in place
+// rather than start a fresh one.
+func isPartialAssistantReply(conversation []provider.Message) bool {
+	return len(conversation) > 0 && conversation[len(conversation)-1].Role == provider.RoleAssistant
+}
+
+// extendTurn appends additionalContent onto the assistant message at the
+// conversation's current leaf, used when continuing a truncated reply.
+func (lc *lambdaChat) extendTurn(userID, convoID, additionalContent string) error {
+	leaf, err := lc.store.Leaf(userID, convoID)
+	if err != nil {
+		return fmt.Errorf("finding conversation leaf: %w", err)
+	}
+	if _, err := lc.store.Extend(userID, convoID, leaf, additionalContent); err != nil {
+		return fmt.Errorf("extending assistant message: %w", err)
+	}
+	return nil
+}
+
+// templateStreamWriter wraps an io.Writer, forwarding only the visible
+// half of a model's streamed output as decided by a template.StreamSplitter,
+// which is what keeps a reasoning marker like "<think>" from leaking out
+// when a stream splits it across chunk boundaries.
+type templateStreamWriter struct {
+	splitter *template.StreamSplitter
+	w        io.Writer
+}
+
+func newTemplateStreamWriter(tpl template.ChatTemplate, w io.Writer) *templateStreamWriter {
+	return &templateStreamWriter{splitter: template.NewStreamSplitter(tpl), w: w}
+}
+
+func (s *templateStreamWriter) Write(p []byte) (int, error) {
+	if text := s.splitter.Write(string(p)); text != "" {
+		if _, err := s.w.Write([]byte(text)); err != nil {
+			return 0, fmt.Errorf("failed to write response: %w", err)
 		}
-		if strings.Contains(content, "</think>") {
-			inThinkingBlock = false
-			responseStarted = true
-			continue
+	}
+	return len(p), nil
+}
+
+// flush forwards any text the splitter withheld because it could still have
+// been the start of a reasoning marker, once the stream has ended and no
+// more input is coming to disambiguate it.
+func (s *templateStreamWriter) flush() error {
+	if text := s.splitter.Flush(); text != "" {
+		if _, err := s.w.Write([]byte(text)); err != nil {
+			return fmt.Errorf("failed to write response: %w", err)
 		}
+	}
+	return nil
+}
 
-		// Skip content inside thinking blocks
-		if inThinkingBlock {
-			continue
+// visible returns all of the output revealed so far.
+func (s *templateStreamWriter) visible() string {
+	return s.splitter.Visible()
+}
+
+// getUserAgent returns the Agent bound to a user's session, or nil if none is bound.
+func (lc *lambdaChat) getUserAgent(userID string) *agent.Agent {
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+	return lc.userAgents[userID]
+}
+
+// agentBindAllowed reports whether userID may bind an agent, per
+// agentAllowlist (see its field doc); an empty allowlist permits everyone.
+func (lc *lambdaChat) agentBindAllowed(userID string) bool {
+	if len(lc.agentAllowlist) == 0 {
+		return true
+	}
+	return lc.agentAllowlist[userID]
+}
+
+// ChatWithTools sends a message along with a set of tools the model may call.
+func (lc *lambdaChat) ChatWithTools(userID, message string, tools []toolbox.ToolSpec) (Reply, error) {
+	return lc.chatWithToolsLoop(userID, message, tools, false)
+}
+
+// maxToolCallRounds bounds the tool-call round-trip loop in
+// chatWithToolsLoop/chatStreamWithToolsLoop, so a model that keeps emitting
+// tool_calls indefinitely can't be driven forever against the live
+// filesystem/shell; once the cap is hit, the loop stops and returns the last
+// assistant content along with an error noting the truncation.
+const maxToolCallRounds = 15
+
+// chatWithToolsLoop is the shared implementation behind ChatWithTools and
+// Chat's agent-bound path. forceAutoRun makes it execute tool calls
+// regardless of lc.autoRunTools, for callers (Chat) whose contract never
+// exposes a Reply.ToolCalls to the caller.
+func (lc *lambdaChat) chatWithToolsLoop(userID, message string, tools []toolbox.ToolSpec, forceAutoRun bool) (Reply, error) {
+	if len(tools) == 0 {
+		if ag := lc.getUserAgent(userID); ag != nil {
+			tools = ag.Tools
 		}
+	}
+
+	convoID, conversation, err := lc.getConversation(userID)
+	if err != nil {
+		return Reply{}, err
+	}
+	userModel := lc.getUserModel(userID)
+	p := lc.providerForModel(userModel)
+
+	conversation = append(conversation, provider.Message{
+		Role:    provider.RoleUser,
+		Content: message,
+	})
 
-		// Only write content after the thinking block has ended
-		if responseStarted {
-			_, err = writer.Write([]byte(content))
-			if err != nil {
-				return fmt.Errorf("failed to write response: %w", err)
+	params := lc.paramsForModel(userModel)
+	params.Model = userModel
+	params.Tools = tools
+
+	var lastContent string
+	for round := 0; round < maxToolCallRounds; round++ {
+		params.Messages = conversation
+		reply, err := p.CreateChatCompletion(lc.ctx, params)
+		if err != nil {
+			return Reply{}, fmt.Errorf("chat completion failed: %w", err)
+		}
+
+		assistantMsg := provider.Message{Role: provider.RoleAssistant, Content: reply.Content, ToolCalls: reply.ToolCalls}
+		conversation = append(conversation, assistantMsg)
+		lastContent = assistantMsg.Content
+
+		if len(reply.ToolCalls) == 0 {
+			if err := lc.recordTurn(userID, convoID, userModel, message, assistantMsg.Content); err != nil {
+				return Reply{}, err
+			}
+			return Reply{Content: assistantMsg.Content}, nil
+		}
+
+		if !lc.autoRunTools && !forceAutoRun {
+			if err := lc.recordTurn(userID, convoID, userModel, message, assistantMsg.Content); err != nil {
+				return Reply{}, err
 			}
+			return Reply{Content: assistantMsg.Content, ToolCalls: reply.ToolCalls}, nil
 		}
 
-		fullResponse.WriteString(content)
+		ag := lc.getUserAgent(userID)
+		if ag == nil {
+			// No bound agent to execute against; surface the tool calls
+			// rather than silently dropping them.
+			if err := lc.recordTurn(userID, convoID, userModel, message, assistantMsg.Content); err != nil {
+				return Reply{}, err
+			}
+			return Reply{Content: assistantMsg.Content, ToolCalls: reply.ToolCalls}, nil
+		}
+
+		toolResults := ag.ExecuteToolCalls(lc.ctx, reply.ToolCalls)
+		lc.reportToolCalls(userID, reply.ToolCalls, toolResults)
+		conversation = append(conversation, toolResults...)
 	}
 
-	// Add the user's message and the assistant's response to the conversation history
-	lc.mu.Lock()
-	lc.conversations[userID] = append(
-		lc.conversations[userID],
-		openai.ChatCompletionMessage{
-			Role:    openai.ChatMessageRoleUser,
-			Content: message,
-		},
-		openai.ChatCompletionMessage{
-			Role:    openai.ChatMessageRoleAssistant,
-			Content: fullResponse.String(),
-		},
-	)
-	lc.mu.Unlock()
+	if err := lc.recordTurn(userID, convoID, userModel, message, lastContent); err != nil {
+		return Reply{}, err
+	}
+	return Reply{Content: lastContent}, fmt.Errorf("tool call loop exceeded %d rounds without a final reply", maxToolCallRounds)
+}
 
-	return nil
+// ChatStreamWithTools is the streaming counterpart of ChatWithTools.
+func (lc *lambdaChat) ChatStreamWithTools(userID, message string, tools []toolbox.ToolSpec, writer io.Writer) (Reply, error) {
+	return lc.chatStreamWithToolsLoop(userID, message, tools, writer, false)
 }
 
-// Reset clears the conversation history for a user
-func (lc *lambdaChat) Reset(userID string) error {
-	// Get the user's current persona
-	userPersona := lc.getUserPersona(userID)
+// chatStreamWithToolsLoop is the streaming counterpart of chatWithToolsLoop;
+// see it for the meaning of forceAutoRun.
+func (lc *lambdaChat) chatStreamWithToolsLoop(userID, message string, tools []toolbox.ToolSpec, writer io.Writer, forceAutoRun bool) (Reply, error) {
+	if len(tools) == 0 {
+		if ag := lc.getUserAgent(userID); ag != nil {
+			tools = ag.Tools
+		}
+	}
 
-	lc.mu.Lock()
-	defer lc.mu.Unlock()
+	convoID, conversation, err := lc.getConversation(userID)
+	if err != nil {
+		return Reply{}, err
+	}
+	userModel := lc.getUserModel(userID)
+	p := lc.providerForModel(userModel)
 
-	// Reset the conversation to just the persona
-	lc.conversations[userID] = []openai.ChatCompletionMessage{
-		{
-			Role:    openai.ChatMessageRoleSystem,
-			Content: userPersona,
-		},
+	conversation = append(conversation, provider.Message{
+		Role:    provider.RoleUser,
+		Content: message,
+	})
+
+	params := lc.paramsForModel(userModel)
+	params.Model = userModel
+	params.Tools = tools
+
+	var lastContent string
+	for round := 0; round < maxToolCallRounds; round++ {
+		params.Messages = conversation
+		reply, err := p.CreateChatCompletionStream(lc.ctx, params, writer)
+		if err != nil {
+			return Reply{}, fmt.Errorf("chat completion stream failed: %w", err)
+		}
+
+		assistantMsg := provider.Message{Role: provider.RoleAssistant, Content: reply.Content, ToolCalls: reply.ToolCalls}
+		conversation = append(conversation, assistantMsg)
+		lastContent = assistantMsg.Content
+
+		if len(reply.ToolCalls) == 0 {
+			if err := lc.recordTurn(userID, convoID, userModel, message, assistantMsg.Content); err != nil {
+				return Reply{}, err
+			}
+			return Reply{Content: assistantMsg.Content}, nil
+		}
+
+		if !lc.autoRunTools && !forceAutoRun {
+			if err := lc.recordTurn(userID, convoID, userModel, message, assistantMsg.Content); err != nil {
+				return Reply{}, err
+			}
+			return Reply{Content: assistantMsg.Content, ToolCalls: reply.ToolCalls}, nil
+		}
+
+		ag := lc.getUserAgent(userID)
+		if ag == nil {
+			if err := lc.recordTurn(userID, convoID, userModel, message, assistantMsg.Content); err != nil {
+				return Reply{}, err
+			}
+			return Reply{Content: assistantMsg.Content, ToolCalls: reply.ToolCalls}, nil
+		}
+
+		toolResults := ag.ExecuteToolCalls(lc.ctx, reply.ToolCalls)
+		lc.reportToolCalls(userID, reply.ToolCalls, toolResults)
+		conversation = append(conversation, toolResults...)
 	}
 
-	return nil
+	if err := lc.recordTurn(userID, convoID, userModel, message, lastContent); err != nil {
+		return Reply{}, err
+	}
+	return Reply{Content: lastContent}, fmt.Errorf("tool call loop exceeded %d rounds without a final reply", maxToolCallRounds)
+}
+
+// reportToolCalls notifies lc.toolObserver (if set) of each tool call's
+// result, pairing calls and their corresponding "tool" role messages
+// positionally, as ExecuteToolCalls returns them.
+func (lc *lambdaChat) reportToolCalls(userID string, calls []provider.ToolCall, results []provider.Message) {
+	if lc.toolObserver == nil {
+		return
+	}
+	for i, call := range calls {
+		if i >= len(results) {
+			break
+		}
+		lc.toolObserver(userID, call.Name, results[i].Content)
+	}
+}
+
+// Reset starts a fresh conversation branch for a user, seeded with their
+// current persona. The previous conversation is not discarded.
+func (lc *lambdaChat) Reset(userID string) error {
+	return lc.resetConversationWithSystemPrompt(userID, lc.getUserPersona(userID))
 }
 
 // SetPersona changes the persona for a specific user and resets their conversation
 func (lc *lambdaChat) SetPersona(userID, personaName string) (string, error) {
-	var newPersona string
-
-	// Determine which persona to use based on the name
-	switch strings.ToLower(personaName) {
-	case "bender", "futurama":
-		newPersona = PersonaBender
-	case "assistant", "helpful":
-		newPersona = PersonaHelpfulAssistant
-	case "writer", "writing":
-		newPersona = PersonaWritingAssistant
-	case "coder", "coding", "programmer":
-		newPersona = PersonaCodingAssistant
-	default:
-		return "", fmt.Errorf("unknown persona: %s", personaName)
+	// If the name matches a registered agent, bind it to the user so its
+	// tools become available via ChatWithTools/ChatStreamWithTools.
+	if ag, ok := lc.agents[strings.ToLower(personaName)]; ok {
+		if !lc.agentBindAllowed(userID) {
+			return "", fmt.Errorf("agent binding is restricted; %s is not on the allowlist for %s", userID, ag.Name)
+		}
+		lc.mu.Lock()
+		lc.userAgents[userID] = ag
+		delete(lc.userPersonas, userID)
+		delete(lc.userPersonaNames, userID)
+		lc.mu.Unlock()
+
+		if err := lc.resetConversationWithSystemPrompt(userID, ag.SystemPrompt); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("*Agent changed to %s.* Your conversation has been reset.", ag.Name), nil
 	}
 
-	lc.mu.Lock()
-	defer lc.mu.Unlock()
+	persona, err := lc.findPersona(personaName)
+	if err != nil {
+		return "", err
+	}
 
-	// Store the user's preferred persona
-	lc.userPersonas[userID] = newPersona
+	lc.mu.Lock()
+	// Store the user's preferred persona, clearing any bound agent
+	lc.userPersonas[userID] = persona.SystemPrompt
+	lc.userPersonaNames[userID] = persona.Name
+	delete(lc.userAgents, userID)
+	lc.mu.Unlock()
+	lc.savePreference(userID, prefKeyPersona, persona.Name)
 
-	// Update the conversation for this user with the new persona
-	lc.conversations[userID] = []openai.ChatCompletionMessage{
-		{
-			Role:    openai.ChatMessageRoleSystem,
-			Content: newPersona,
-		},
+	if err := lc.resetConversationWithSystemPrompt(userID, persona.SystemPrompt); err != nil {
+		return "", err
 	}
 
-	// Return a confirmation message with information about the new persona
-	var description string
-	switch strings.ToLower(personaName) {
-	case "bender", "futurama":
-		description = "Bender from Futurama"
-	case "assistant", "helpful":
-		description = "Helpful Assistant"
-	case "writer", "writing":
-		description = "Writing Assistant"
-	case "coder", "coding", "programmer":
-		description = "Coding Assistant"
-	}
+	return fmt.Sprintf("*Persona changed to %s.* Your conversation has been reset.", persona.Description), nil
+}
+
+// SetSystemPrompt starts a fresh conversation for userID seeded with an
+// arbitrary system prompt, clearing any persona or agent previously bound
+// to userID.
+func (lc *lambdaChat) SetSystemPrompt(userID, prompt string) error {
+	lc.mu.Lock()
+	delete(lc.userPersonas, userID)
+	delete(lc.userPersonaNames, userID)
+	delete(lc.userAgents, userID)
+	lc.mu.Unlock()
 
-	return fmt.Sprintf("*Persona changed to %s.* Your conversation has been reset.", description), nil
+	return lc.resetConversationWithSystemPrompt(userID, prompt)
 }
 
 // GetAvailablePersonas returns a list of available personas
 func (lc *lambdaChat) GetAvailablePersonas() []string {
-	return []string{
-		"*bender* - Bender from Futurama",
-		"*assistant* - Helpful Assistant",
-		"*writer* - Writing Assistant",
-		"*coder* - Coding Assistant",
+	personas := make([]string, 0, len(lc.availablePersonas))
+	for _, persona := range lc.availablePersonas {
+		personas = append(personas, fmt.Sprintf("*%s* - %s", persona.Name, persona.Description))
 	}
+	return personas
 }
 
 // GetAvailableModels returns a list of available models
@@ -643,3 +1710,220 @@ func (lc *lambdaChat) GetAvailableModels() []string {
 	}
 	return models
 }
+
+// ModelInfo returns structured metadata for every available model.
+func (lc *lambdaChat) ModelInfo() []ModelSummary {
+	summaries := make([]ModelSummary, 0, len(lc.availableModels))
+	for _, model := range lc.availableModels {
+		summaries = append(summaries, ModelSummary{
+			Name:          model.ID,
+			Description:   model.DisplayName,
+			ContextLength: model.ContextLength,
+		})
+	}
+	return summaries
+}
+
+// PersonaInfo returns structured metadata for every available persona.
+func (lc *lambdaChat) PersonaInfo() []PersonaSummary {
+	summaries := make([]PersonaSummary, 0, len(lc.availablePersonas))
+	for _, persona := range lc.availablePersonas {
+		summaries = append(summaries, PersonaSummary{
+			Name:        persona.Name,
+			Description: persona.Description,
+		})
+	}
+	return summaries
+}
+
+// AgentInfo returns structured metadata for every registered agent, for
+// clients that want to list or render them (e.g. `/agent` with no name).
+// Bind one to a user session via SetPersona, the same as a persona.
+func (lc *lambdaChat) AgentInfo() []AgentSummary {
+	summaries := make([]AgentSummary, 0, len(lc.agents))
+	for _, ag := range lc.agents {
+		summaries = append(summaries, AgentSummary{
+			Name:      ag.Name,
+			ToolNames: toolNames(ag.Tools),
+		})
+	}
+	return summaries
+}
+
+// HealthInfo returns every available model's current fallback-routing
+// health. A model with no recorded attempts yet is reported healthy with a
+// zero SuccessRate.
+func (lc *lambdaChat) HealthInfo() []ModelHealthSummary {
+	lc.healthMu.Lock()
+	defer lc.healthMu.Unlock()
+
+	summaries := make([]ModelHealthSummary, 0, len(lc.availableModels))
+	for _, model := range lc.availableModels {
+		h, ok := lc.modelHealth[model.ID]
+		if !ok {
+			summaries = append(summaries, ModelHealthSummary{Model: model.ID, Healthy: true})
+			continue
+		}
+
+		var successRate float64
+		if h.totalCount > 0 {
+			successRate = float64(h.successCount) / float64(h.totalCount)
+		}
+		summaries = append(summaries, ModelHealthSummary{
+			Model:               model.ID,
+			Healthy:             !time.Now().Before(h.cooldownUntil),
+			ConsecutiveFailures: h.consecutiveFailures,
+			CooldownUntil:       h.cooldownUntil,
+			LastError:           h.lastError,
+			LastLatency:         h.lastLatency,
+			SuccessRate:         successRate,
+		})
+	}
+	return summaries
+}
+
+// toolNames returns the Name of each tool in tools, for display.
+func toolNames(tools []toolbox.ToolSpec) []string {
+	names := make([]string, len(tools))
+	for i, t := range tools {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// NewConversation starts a fresh, empty conversation for userID and makes it
+// the active one, returning its ID.
+func (lc *lambdaChat) NewConversation(userID string) (string, error) {
+	return lc.newConversationLocked(userID, lc.getUserPersona(userID))
+}
+
+// SwitchConversation makes convoID the active conversation for userID.
+func (lc *lambdaChat) SwitchConversation(userID, convoID string) error {
+	if _, err := lc.store.History(userID, convoID); err != nil {
+		return err
+	}
+
+	lc.mu.Lock()
+	lc.userConvo[userID] = convoID
+	lc.mu.Unlock()
+	return nil
+}
+
+// ListConversations returns metadata for every conversation belonging to
+// userID, most recently updated first.
+func (lc *lambdaChat) ListConversations(userID string) ([]convstore.ConversationMeta, error) {
+	return lc.store.List(userID)
+}
+
+// DeleteConversation permanently removes a conversation. If it was the
+// user's active conversation, they're left without one until their next
+// message starts a fresh one.
+func (lc *lambdaChat) DeleteConversation(userID, convoID string) error {
+	if err := lc.store.Delete(userID, convoID); err != nil {
+		return err
+	}
+
+	lc.mu.Lock()
+	if lc.userConvo[userID] == convoID {
+		delete(lc.userConvo, userID)
+	}
+	lc.mu.Unlock()
+	return nil
+}
+
+// RenameConversation sets a conversation's display title, overriding its
+// auto-generated one.
+func (lc *lambdaChat) RenameConversation(userID, convoID, title string) error {
+	return lc.store.Rename(userID, convoID, title)
+}
+
+// Fork branches a new conversation from messageID within convoID and
+// switches userID to it, returning the new conversation's ID.
+func (lc *lambdaChat) Fork(userID, convoID, messageID string) (string, error) {
+	newConvoID, err := lc.store.Fork(userID, convoID, messageID)
+	if err != nil {
+		return "", err
+	}
+
+	lc.mu.Lock()
+	lc.userConvo[userID] = newConvoID
+	lc.mu.Unlock()
+	return newConvoID, nil
+}
+
+// Edit rewrites messageID's content, discards any messages that came after
+// it on that branch, and re-prompts the model from that point, returning the
+// new assistant reply.
+func (lc *lambdaChat) Edit(userID, convoID, messageID, newContent string) (string, error) {
+	if _, err := lc.store.Edit(userID, convoID, messageID, newContent); err != nil {
+		return "", err
+	}
+
+	history, err := lc.store.History(userID, convoID)
+	if err != nil {
+		return "", fmt.Errorf("loading conversation history: %w", err)
+	}
+
+	conversation := make([]provider.Message, 0, len(history))
+	for _, m := range history {
+		conversation = append(conversation, provider.Message{
+			Role:    m.Role,
+			Content: m.Content,
+		})
+	}
+
+	userModel := lc.getUserModel(userID)
+	reply, modelUsed, err := lc.chatCompletionWithFallback(conversation, userModel)
+	if err != nil {
+		return "", fmt.Errorf("chat completion failed: %w", err)
+	}
+
+	assistantContent, _ := lc.templateForModel(modelUsed).ParseThinking(reply.Content)
+	if modelUsed != userModel {
+		assistantContent = fmt.Sprintf("_(note: %s was unavailable; answered by %s)_\n\n%s", userModel, modelUsed, assistantContent)
+	}
+
+	if _, err := lc.store.AppendMessage(userID, convoID, messageID, convstore.Message{
+		Role:    provider.RoleAssistant,
+		Content: assistantContent,
+		Model:   modelUsed,
+	}); err != nil {
+		return "", fmt.Errorf("recording assistant message: %w", err)
+	}
+
+	return assistantContent, nil
+}
+
+// Regenerate discards the active conversation's last assistant reply and
+// re-prompts the model with the same last user message, returning the new
+// reply.
+func (lc *lambdaChat) Regenerate(userID string) (string, error) {
+	convoID, err := lc.ensureConversation(userID)
+	if err != nil {
+		return "", err
+	}
+
+	history, err := lc.store.History(userID, convoID)
+	if err != nil {
+		return "", fmt.Errorf("loading conversation history: %w", err)
+	}
+
+	var lastUser *convstore.Message
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Role == provider.RoleUser {
+			lastUser = &history[i]
+			break
+		}
+	}
+	if lastUser == nil {
+		return "", fmt.Errorf("conversation %s has no user message to regenerate a reply for", convoID)
+	}
+
+	return lc.Edit(userID, convoID, lastUser.ID, lastUser.Content)
+}
+
+// Close cancels the client's internal context, aborting any in-flight
+// request immediately instead of waiting for it to finish.
+func (lc *lambdaChat) Close() {
+	lc.done()
+}
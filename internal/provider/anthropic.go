@@ -0,0 +1,286 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/lambda/lambdachat-slackbot/internal/agent/toolbox"
+)
+
+// AnthropicURL is the default Anthropic Messages API endpoint.
+const AnthropicURL string = "https://api.anthropic.com"
+
+const anthropicVersion = "2023-06-01"
+
+// defaultMaxTokens is sent when a Request leaves MaxTokens unset, since the
+// Messages API requires max_tokens.
+const defaultMaxTokens = 4096
+
+// anthropicProvider talks to Anthropic's /v1/messages endpoint, translating
+// its content-block format (including tool_use/tool_result) to and from the
+// provider-agnostic Message/ToolCall types.
+type anthropicProvider struct {
+	url, apiKey string
+	client      *http.Client
+}
+
+// NewAnthropic creates a provider for the Anthropic Messages API. headers,
+// if non-empty, are added to every outgoing request.
+func NewAnthropic(url, apiKey string, headers map[string]string) ChatCompletionProvider {
+	if url == "" {
+		url = AnthropicURL
+	}
+	client := http.DefaultClient
+	if len(headers) > 0 {
+		client = &http.Client{Transport: withHeaders(http.DefaultTransport, headers)}
+	}
+	return &anthropicProvider{url: strings.TrimSuffix(url, "/"), apiKey: apiKey, client: client}
+}
+
+func (p *anthropicProvider) Name() string {
+	return NameAnthropic
+}
+
+type anthropicContentBlock struct {
+	Type string `json:"type"`
+
+	// type == "text"
+	Text string `json:"text,omitempty"`
+
+	// type == "tool_use"
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+
+	// type == "tool_result"
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	Content   string `json:"content,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string                  `json:"role"`
+	Content []anthropicContentBlock `json:"content"`
+}
+
+type anthropicTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	Tools       []anthropicTool    `json:"tools,omitempty"`
+	MaxTokens   int                `json:"max_tokens"`
+	Stream      bool               `json:"stream,omitempty"`
+	Temperature *float64           `json:"temperature,omitempty"`
+	TopP        *float64           `json:"top_p,omitempty"`
+}
+
+type anthropicResponse struct {
+	Content []anthropicContentBlock `json:"content"`
+}
+
+func (p *anthropicProvider) CreateChatCompletion(ctx context.Context, req Request) (Reply, error) {
+	body, err := p.do(ctx, req, false)
+	if err != nil {
+		return Reply{}, err
+	}
+	defer body.Close()
+
+	var resp anthropicResponse
+	if err := json.NewDecoder(body).Decode(&resp); err != nil {
+		return Reply{}, fmt.Errorf("anthropic: decoding response: %w", err)
+	}
+	return blocksToReply(resp.Content), nil
+}
+
+func (p *anthropicProvider) CreateChatCompletionStream(ctx context.Context, req Request, w io.Writer) (Reply, error) {
+	body, err := p.do(ctx, req, true)
+	if err != nil {
+		return Reply{}, err
+	}
+	defer body.Close()
+
+	var content strings.Builder
+	var toolCalls []ToolCall
+	var current struct {
+		id, name string
+		input    strings.Builder
+		isTool   bool
+	}
+
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		data := strings.TrimPrefix(line, "data: ")
+
+		var event struct {
+			Type         string `json:"type"`
+			ContentBlock struct {
+				Type string `json:"type"`
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"content_block"`
+			Delta struct {
+				Type        string `json:"type"`
+				Text        string `json:"text"`
+				PartialJSON string `json:"partial_json"`
+			} `json:"delta"`
+		}
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			continue // ignore events this adapter doesn't need to parse
+		}
+
+		switch event.Type {
+		case "content_block_start":
+			current.isTool = event.ContentBlock.Type == "tool_use"
+			current.id = event.ContentBlock.ID
+			current.name = event.ContentBlock.Name
+			current.input.Reset()
+		case "content_block_delta":
+			if event.Delta.Type == "text_delta" && event.Delta.Text != "" {
+				content.WriteString(event.Delta.Text)
+				if _, err := w.Write([]byte(event.Delta.Text)); err != nil {
+					return Reply{}, fmt.Errorf("failed to write response: %w", err)
+				}
+			}
+			if event.Delta.Type == "input_json_delta" {
+				current.input.WriteString(event.Delta.PartialJSON)
+			}
+		case "content_block_stop":
+			if current.isTool {
+				toolCalls = append(toolCalls, ToolCall{ID: current.id, Name: current.name, Arguments: current.input.String()})
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Reply{}, fmt.Errorf("anthropic: reading stream: %w", err)
+	}
+
+	return Reply{Content: content.String(), ToolCalls: toolCalls}, nil
+}
+
+func (p *anthropicProvider) do(ctx context.Context, req Request, stream bool) (io.ReadCloser, error) {
+	system, rest := splitSystemPrompt(req.Messages)
+
+	maxTokens := defaultMaxTokens
+	if req.MaxTokens != nil {
+		maxTokens = *req.MaxTokens
+	}
+
+	payload := anthropicRequest{
+		Model:       req.Model,
+		System:      system,
+		Messages:    toAnthropicMessages(rest),
+		Tools:       toAnthropicTools(req.Tools),
+		MaxTokens:   maxTokens,
+		Stream:      stream,
+		Temperature: req.Temperature,
+		TopP:        req.TopP,
+	}
+
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("anthropic: encoding request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url+"/v1/messages", bytes.NewReader(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("anthropic: building request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", p.apiKey)
+	httpReq.Header.Set("anthropic-version", anthropicVersion)
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("anthropic: request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, NewStatusError(resp.StatusCode, fmt.Errorf("anthropic: unexpected status %s: %s", resp.Status, body))
+	}
+	return resp.Body, nil
+}
+
+// toAnthropicMessages translates messages into Anthropic's content-block
+// format, folding "tool" role messages into a user message carrying a
+// tool_result block instead of a distinct role.
+func toAnthropicMessages(messages []Message) []anthropicMessage {
+	out := make([]anthropicMessage, 0, len(messages))
+	for _, m := range messages {
+		switch m.Role {
+		case RoleTool:
+			out = append(out, anthropicMessage{
+				Role: "user",
+				Content: []anthropicContentBlock{{
+					Type:      "tool_result",
+					ToolUseID: m.ToolCallID,
+					Content:   m.Content,
+				}},
+			})
+		case RoleAssistant:
+			blocks := []anthropicContentBlock{}
+			if m.Content != "" {
+				blocks = append(blocks, anthropicContentBlock{Type: "text", Text: m.Content})
+			}
+			for _, tc := range m.ToolCalls {
+				blocks = append(blocks, anthropicContentBlock{
+					Type:  "tool_use",
+					ID:    tc.ID,
+					Name:  tc.Name,
+					Input: json.RawMessage(tc.Arguments),
+				})
+			}
+			out = append(out, anthropicMessage{Role: "assistant", Content: blocks})
+		default:
+			out = append(out, anthropicMessage{
+				Role:    "user",
+				Content: []anthropicContentBlock{{Type: "text", Text: m.Content}},
+			})
+		}
+	}
+	return out
+}
+
+func toAnthropicTools(tools []toolbox.ToolSpec) []anthropicTool {
+	if len(tools) == 0 {
+		return nil
+	}
+	out := make([]anthropicTool, 0, len(tools))
+	for _, t := range tools {
+		out = append(out, anthropicTool{
+			Name:        t.Name,
+			Description: t.Description,
+			InputSchema: t.Parameters,
+		})
+	}
+	return out
+}
+
+func blocksToReply(blocks []anthropicContentBlock) Reply {
+	var content strings.Builder
+	var toolCalls []ToolCall
+	for _, b := range blocks {
+		switch b.Type {
+		case "text":
+			content.WriteString(b.Text)
+		case "tool_use":
+			toolCalls = append(toolCalls, ToolCall{ID: b.ID, Name: b.Name, Arguments: string(b.Input)})
+		}
+	}
+	return Reply{Content: content.String(), ToolCalls: toolCalls}
+}
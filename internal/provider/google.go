@@ -0,0 +1,261 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/lambda/lambdachat-slackbot/internal/agent/toolbox"
+)
+
+// GoogleURL is the default Google Generative Language API endpoint.
+const GoogleURL string = "https://generativelanguage.googleapis.com"
+
+// googleProvider talks to the Gemini generateContent API, translating its
+// functionCall/functionResponse parts to and from ToolCall.
+type googleProvider struct {
+	url, apiKey string
+	client      *http.Client
+}
+
+// NewGoogle creates a provider for the Gemini API. headers, if non-empty,
+// are added to every outgoing request.
+func NewGoogle(url, apiKey string, headers map[string]string) ChatCompletionProvider {
+	if url == "" {
+		url = GoogleURL
+	}
+	client := http.DefaultClient
+	if len(headers) > 0 {
+		client = &http.Client{Transport: withHeaders(http.DefaultTransport, headers)}
+	}
+	return &googleProvider{url: strings.TrimSuffix(url, "/"), apiKey: apiKey, client: client}
+}
+
+func (p *googleProvider) Name() string {
+	return NameGoogle
+}
+
+type googlePart struct {
+	Text         string              `json:"text,omitempty"`
+	FunctionCall *googleFunctionCall `json:"functionCall,omitempty"`
+	FunctionResp *googleFunctionResp `json:"functionResponse,omitempty"`
+}
+
+type googleFunctionCall struct {
+	Name string          `json:"name"`
+	Args json.RawMessage `json:"args"`
+}
+
+type googleFunctionResp struct {
+	Name     string          `json:"name"`
+	Response json.RawMessage `json:"response"`
+}
+
+type googleContent struct {
+	Role  string       `json:"role"`
+	Parts []googlePart `json:"parts"`
+}
+
+type googleFunctionDecl struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"`
+}
+
+type googleTool struct {
+	FunctionDeclarations []googleFunctionDecl `json:"functionDeclarations"`
+}
+
+type googleRequest struct {
+	Contents          []googleContent         `json:"contents"`
+	SystemInstruction *googleContent          `json:"systemInstruction,omitempty"`
+	Tools             []googleTool            `json:"tools,omitempty"`
+	GenerationConfig  *googleGenerationConfig `json:"generationConfig,omitempty"`
+}
+
+// googleGenerationConfig carries the subset of Gemini's generationConfig
+// that Request exposes. Fields are pointers so an unset parameter is
+// omitted rather than sent as an explicit zero.
+type googleGenerationConfig struct {
+	Temperature     *float64 `json:"temperature,omitempty"`
+	TopP            *float64 `json:"topP,omitempty"`
+	MaxOutputTokens *int     `json:"maxOutputTokens,omitempty"`
+}
+
+type googleResponse struct {
+	Candidates []struct {
+		Content googleContent `json:"content"`
+	} `json:"candidates"`
+}
+
+func (p *googleProvider) CreateChatCompletion(ctx context.Context, req Request) (Reply, error) {
+	body, err := p.do(ctx, req, "generateContent")
+	if err != nil {
+		return Reply{}, err
+	}
+	defer body.Close()
+
+	var resp googleResponse
+	if err := json.NewDecoder(body).Decode(&resp); err != nil {
+		return Reply{}, fmt.Errorf("google: decoding response: %w", err)
+	}
+	if len(resp.Candidates) == 0 {
+		return Reply{}, fmt.Errorf("google: no candidates returned")
+	}
+	return partsToReply(resp.Candidates[0].Content.Parts), nil
+}
+
+func (p *googleProvider) CreateChatCompletionStream(ctx context.Context, req Request, w io.Writer) (Reply, error) {
+	body, err := p.do(ctx, req, "streamGenerateContent?alt=sse")
+	if err != nil {
+		return Reply{}, err
+	}
+	defer body.Close()
+
+	var content strings.Builder
+	var toolCalls []ToolCall
+
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+
+		var chunk googleResponse
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &chunk); err != nil {
+			return Reply{}, fmt.Errorf("google: decoding stream chunk: %w", err)
+		}
+		if len(chunk.Candidates) == 0 {
+			continue
+		}
+
+		for _, part := range chunk.Candidates[0].Content.Parts {
+			if part.Text != "" {
+				content.WriteString(part.Text)
+				if _, err := w.Write([]byte(part.Text)); err != nil {
+					return Reply{}, fmt.Errorf("failed to write response: %w", err)
+				}
+			}
+			if part.FunctionCall != nil {
+				toolCalls = append(toolCalls, ToolCall{Name: part.FunctionCall.Name, Arguments: string(part.FunctionCall.Args)})
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Reply{}, fmt.Errorf("google: reading stream: %w", err)
+	}
+
+	return Reply{Content: content.String(), ToolCalls: toolCalls}, nil
+}
+
+func (p *googleProvider) do(ctx context.Context, req Request, method string) (io.ReadCloser, error) {
+	system, rest := splitSystemPrompt(req.Messages)
+
+	payload := googleRequest{
+		Contents: toGoogleContents(rest),
+		Tools:    toGoogleTools(req.Tools),
+	}
+	if system != "" {
+		payload.SystemInstruction = &googleContent{Parts: []googlePart{{Text: system}}}
+	}
+	if req.Temperature != nil || req.TopP != nil || req.MaxTokens != nil {
+		payload.GenerationConfig = &googleGenerationConfig{
+			Temperature:     req.Temperature,
+			TopP:            req.TopP,
+			MaxOutputTokens: req.MaxTokens,
+		}
+	}
+
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("google: encoding request: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/v1beta/models/%s:%s", p.url, req.Model, method)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("google: building request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-goog-api-key", p.apiKey)
+	query, _ := url.ParseQuery(httpReq.URL.RawQuery)
+	query.Set("key", p.apiKey)
+	httpReq.URL.RawQuery = query.Encode()
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("google: request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, NewStatusError(resp.StatusCode, fmt.Errorf("google: unexpected status %s: %s", resp.Status, body))
+	}
+	return resp.Body, nil
+}
+
+// toGoogleContents translates messages into Gemini's "user"/"model" roles,
+// folding "tool" role messages into a user turn carrying a functionResponse
+// part instead of a distinct role.
+func toGoogleContents(messages []Message) []googleContent {
+	out := make([]googleContent, 0, len(messages))
+	for _, m := range messages {
+		switch m.Role {
+		case RoleTool:
+			out = append(out, googleContent{
+				Role: "user",
+				Parts: []googlePart{{
+					FunctionResp: &googleFunctionResp{Name: m.Name, Response: json.RawMessage(fmt.Sprintf(`{"content":%q}`, m.Content))},
+				}},
+			})
+		case RoleAssistant:
+			parts := []googlePart{}
+			if m.Content != "" {
+				parts = append(parts, googlePart{Text: m.Content})
+			}
+			for _, tc := range m.ToolCalls {
+				parts = append(parts, googlePart{FunctionCall: &googleFunctionCall{Name: tc.Name, Args: json.RawMessage(tc.Arguments)}})
+			}
+			out = append(out, googleContent{Role: "model", Parts: parts})
+		default:
+			out = append(out, googleContent{Role: "user", Parts: []googlePart{{Text: m.Content}}})
+		}
+	}
+	return out
+}
+
+func toGoogleTools(tools []toolbox.ToolSpec) []googleTool {
+	if len(tools) == 0 {
+		return nil
+	}
+	decls := make([]googleFunctionDecl, 0, len(tools))
+	for _, t := range tools {
+		decls = append(decls, googleFunctionDecl{
+			Name:        t.Name,
+			Description: t.Description,
+			Parameters:  t.Parameters,
+		})
+	}
+	return []googleTool{{FunctionDeclarations: decls}}
+}
+
+func partsToReply(parts []googlePart) Reply {
+	var content strings.Builder
+	var toolCalls []ToolCall
+	for _, part := range parts {
+		if part.Text != "" {
+			content.WriteString(part.Text)
+		}
+		if part.FunctionCall != nil {
+			toolCalls = append(toolCalls, ToolCall{Name: part.FunctionCall.Name, Arguments: string(part.FunctionCall.Args)})
+		}
+	}
+	return Reply{Content: content.String(), ToolCalls: toolCalls}
+}
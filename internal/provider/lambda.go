@@ -0,0 +1,236 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/lambda/lambdachat-slackbot/internal/agent/toolbox"
+	"github.com/sirupsen/logrus"
+)
+
+// LambdaChatURL is the OpenAPI endpoint for Lambda Chat.
+const LambdaChatURL string = "https://api.lambdalabs.com/v1"
+
+// lambdaProvider talks to Lambda Chat (and any other OpenAI-compatible
+// endpoint) via the go-openai client. This is the client's original,
+// built-in provider.
+type lambdaProvider struct {
+	client *openai.Client
+}
+
+// loggingTransport logs every outgoing request's method and URL.
+type loggingTransport struct {
+	transport http.RoundTripper
+	l         *logrus.Entry
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.l.Infof("Method %q Request URL %q", req.Method, req.URL.String())
+	return t.transport.RoundTrip(req)
+}
+
+// NewLambda creates the OpenAI-compatible provider used for Lambda Chat.
+// headers, if non-empty, are added to every outgoing request (e.g. for a
+// reverse proxy in front of an OpenAI-compatible endpoint).
+func NewLambda(l *logrus.Entry, url, token string, headers map[string]string) ChatCompletionProvider {
+	if url == "" {
+		url = LambdaChatURL
+	}
+
+	cfg := openai.DefaultConfig(token)
+	cfg.BaseURL = url
+	cfg.HTTPClient = &http.Client{
+		Transport: withHeaders(&loggingTransport{http.DefaultTransport, l}, headers),
+	}
+
+	return &lambdaProvider{client: openai.NewClientWithConfig(cfg)}
+}
+
+func (p *lambdaProvider) Name() string {
+	return NameLambda
+}
+
+// ListModels returns the model IDs the backend reports via its /models
+// endpoint.
+func (p *lambdaProvider) ListModels(ctx context.Context) ([]string, error) {
+	list, err := p.client.ListModels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing models: %w", err)
+	}
+	ids := make([]string, 0, len(list.Models))
+	for _, m := range list.Models {
+		ids = append(ids, m.ID)
+	}
+	return ids, nil
+}
+
+func (p *lambdaProvider) CreateChatCompletion(ctx context.Context, req Request) (Reply, error) {
+	resp, err := p.client.CreateChatCompletion(ctx, applyParams(openai.ChatCompletionRequest{
+		Model:    req.Model,
+		Messages: toOpenAIMessages(req.Messages),
+		Tools:    toOpenAITools(req.Tools),
+	}, req))
+	if err != nil {
+		return Reply{}, fmt.Errorf("chat completion failed: %w", wrapOpenAIStatusError(err))
+	}
+	if len(resp.Choices) == 0 {
+		return Reply{}, fmt.Errorf("no choices returned")
+	}
+
+	msg := resp.Choices[0].Message
+	return Reply{Content: msg.Content, ToolCalls: fromOpenAIToolCalls(msg.ToolCalls)}, nil
+}
+
+func (p *lambdaProvider) CreateChatCompletionStream(ctx context.Context, req Request, w io.Writer) (Reply, error) {
+	stream, err := p.client.CreateChatCompletionStream(ctx, applyParams(openai.ChatCompletionRequest{
+		Model:    req.Model,
+		Messages: toOpenAIMessages(req.Messages),
+		Tools:    toOpenAITools(req.Tools),
+		Stream:   true,
+	}, req))
+	if err != nil {
+		return Reply{}, fmt.Errorf("chat completion stream failed: %w", wrapOpenAIStatusError(err))
+	}
+	defer stream.Close()
+
+	var content strings.Builder
+	var toolCalls []openai.ToolCall
+
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return Reply{}, fmt.Errorf("stream receive error: %w", err)
+		}
+		if len(resp.Choices) == 0 {
+			continue
+		}
+
+		delta := resp.Choices[0].Delta
+		if delta.Content != "" {
+			content.WriteString(delta.Content)
+			if _, err := w.Write([]byte(delta.Content)); err != nil {
+				return Reply{}, fmt.Errorf("failed to write response: %w", err)
+			}
+		}
+
+		for _, tc := range delta.ToolCalls {
+			idx := 0
+			if tc.Index != nil {
+				idx = *tc.Index
+			}
+			for len(toolCalls) <= idx {
+				toolCalls = append(toolCalls, openai.ToolCall{Type: openai.ToolTypeFunction})
+			}
+			if tc.ID != "" {
+				toolCalls[idx].ID = tc.ID
+			}
+			if tc.Function.Name != "" {
+				toolCalls[idx].Function.Name += tc.Function.Name
+			}
+			toolCalls[idx].Function.Arguments += tc.Function.Arguments
+		}
+	}
+
+	return Reply{Content: content.String(), ToolCalls: fromOpenAIToolCalls(toolCalls)}, nil
+}
+
+// wrapOpenAIStatusError wraps err as a StatusError if the go-openai client
+// reports an HTTP status for it, so callers can tell a transient failure
+// (5xx, 429) from a permanent one without depending on go-openai's own
+// error types.
+func wrapOpenAIStatusError(err error) error {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) && apiErr.HTTPStatusCode > 0 {
+		return NewStatusError(apiErr.HTTPStatusCode, err)
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) && reqErr.HTTPStatusCode > 0 {
+		return NewStatusError(reqErr.HTTPStatusCode, err)
+	}
+	return err
+}
+
+func toOpenAIMessages(messages []Message) []openai.ChatCompletionMessage {
+	out := make([]openai.ChatCompletionMessage, 0, len(messages))
+	for _, m := range messages {
+		out = append(out, openai.ChatCompletionMessage{
+			Role:       m.Role,
+			Content:    m.Content,
+			Name:       m.Name,
+			ToolCallID: m.ToolCallID,
+			ToolCalls:  toOpenAIToolCalls(m.ToolCalls),
+		})
+	}
+	return out
+}
+
+func toOpenAIToolCalls(calls []ToolCall) []openai.ToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	out := make([]openai.ToolCall, 0, len(calls))
+	for _, c := range calls {
+		out = append(out, openai.ToolCall{
+			ID:   c.ID,
+			Type: openai.ToolTypeFunction,
+			Function: openai.FunctionCall{
+				Name:      c.Name,
+				Arguments: c.Arguments,
+			},
+		})
+	}
+	return out
+}
+
+func fromOpenAIToolCalls(calls []openai.ToolCall) []ToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	out := make([]ToolCall, 0, len(calls))
+	for _, c := range calls {
+		out = append(out, ToolCall{ID: c.ID, Name: c.Function.Name, Arguments: c.Function.Arguments})
+	}
+	return out
+}
+
+// applyParams copies req's optional generation parameters onto oaiReq,
+// leaving go-openai's own zero-value defaults in place for anything unset.
+func applyParams(oaiReq openai.ChatCompletionRequest, req Request) openai.ChatCompletionRequest {
+	if req.Temperature != nil {
+		oaiReq.Temperature = float32(*req.Temperature)
+	}
+	if req.TopP != nil {
+		oaiReq.TopP = float32(*req.TopP)
+	}
+	if req.MaxTokens != nil {
+		oaiReq.MaxTokens = *req.MaxTokens
+	}
+	return oaiReq
+}
+
+func toOpenAITools(tools []toolbox.ToolSpec) []openai.Tool {
+	if len(tools) == 0 {
+		return nil
+	}
+	out := make([]openai.Tool, 0, len(tools))
+	for _, t := range tools {
+		out = append(out, openai.Tool{
+			Type: openai.ToolTypeFunction,
+			Function: &openai.FunctionDefinition{
+				Name:        t.Name,
+				Description: t.Description,
+				Parameters:  t.Parameters,
+			},
+		})
+	}
+	return out
+}
@@ -0,0 +1,215 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/lambda/lambdachat-slackbot/internal/agent/toolbox"
+)
+
+// ollamaProvider talks to an Ollama server's native /api/chat endpoint.
+type ollamaProvider struct {
+	url    string
+	client *http.Client
+}
+
+// NewOllama creates a provider for an Ollama server at url (e.g.
+// "http://localhost:11434"). headers, if non-empty, are added to every
+// outgoing request.
+func NewOllama(url string, headers map[string]string) ChatCompletionProvider {
+	client := http.DefaultClient
+	if len(headers) > 0 {
+		client = &http.Client{Transport: withHeaders(http.DefaultTransport, headers)}
+	}
+	return &ollamaProvider{url: strings.TrimSuffix(url, "/"), client: client}
+}
+
+func (p *ollamaProvider) Name() string {
+	return NameOllama
+}
+
+type ollamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+}
+
+type ollamaToolCall struct {
+	Function ollamaToolCallFunction `json:"function"`
+}
+
+type ollamaToolCallFunction struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+type ollamaTool struct {
+	Type     string             `json:"type"`
+	Function ollamaToolFunction `json:"function"`
+}
+
+type ollamaToolFunction struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Parameters  json.RawMessage `json:"parameters"`
+}
+
+type ollamaRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Tools    []ollamaTool    `json:"tools,omitempty"`
+	Stream   bool            `json:"stream"`
+	Options  *ollamaOptions  `json:"options,omitempty"`
+}
+
+// ollamaOptions carries the subset of Ollama's generation options that
+// Request exposes. Fields are pointers so an unset parameter is omitted
+// rather than sent as an explicit zero.
+type ollamaOptions struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	NumPredict  *int     `json:"num_predict,omitempty"`
+}
+
+type ollamaResponse struct {
+	Message ollamaMessage `json:"message"`
+	Done    bool          `json:"done"`
+}
+
+func (p *ollamaProvider) CreateChatCompletion(ctx context.Context, req Request) (Reply, error) {
+	body, err := p.do(ctx, req, false)
+	if err != nil {
+		return Reply{}, err
+	}
+	defer body.Close()
+
+	var resp ollamaResponse
+	if err := json.NewDecoder(body).Decode(&resp); err != nil {
+		return Reply{}, fmt.Errorf("ollama: decoding response: %w", err)
+	}
+	return Reply{Content: resp.Message.Content, ToolCalls: fromOllamaToolCalls(resp.Message.ToolCalls)}, nil
+}
+
+func (p *ollamaProvider) CreateChatCompletionStream(ctx context.Context, req Request, w io.Writer) (Reply, error) {
+	body, err := p.do(ctx, req, true)
+	if err != nil {
+		return Reply{}, err
+	}
+	defer body.Close()
+
+	var content strings.Builder
+	var toolCalls []ollamaToolCall
+
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var chunk ollamaResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return Reply{}, fmt.Errorf("ollama: decoding stream chunk: %w", err)
+		}
+
+		if chunk.Message.Content != "" {
+			content.WriteString(chunk.Message.Content)
+			if _, err := w.Write([]byte(chunk.Message.Content)); err != nil {
+				return Reply{}, fmt.Errorf("failed to write response: %w", err)
+			}
+		}
+		toolCalls = append(toolCalls, chunk.Message.ToolCalls...)
+	}
+	if err := scanner.Err(); err != nil {
+		return Reply{}, fmt.Errorf("ollama: reading stream: %w", err)
+	}
+
+	return Reply{Content: content.String(), ToolCalls: fromOllamaToolCalls(toolCalls)}, nil
+}
+
+func (p *ollamaProvider) do(ctx context.Context, req Request, stream bool) (io.ReadCloser, error) {
+	payload := ollamaRequest{
+		Model:    req.Model,
+		Messages: toOllamaMessages(req.Messages),
+		Tools:    toOllamaTools(req.Tools),
+		Stream:   stream,
+	}
+	if req.Temperature != nil || req.TopP != nil || req.MaxTokens != nil {
+		payload.Options = &ollamaOptions{
+			Temperature: req.Temperature,
+			TopP:        req.TopP,
+			NumPredict:  req.MaxTokens,
+		}
+	}
+
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("ollama: encoding request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url+"/api/chat", bytes.NewReader(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("ollama: building request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("ollama: request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, NewStatusError(resp.StatusCode, fmt.Errorf("ollama: unexpected status %s: %s", resp.Status, body))
+	}
+	return resp.Body, nil
+}
+
+func toOllamaMessages(messages []Message) []ollamaMessage {
+	out := make([]ollamaMessage, 0, len(messages))
+	for _, m := range messages {
+		role := m.Role
+		if role == RoleTool {
+			// Ollama has no distinct "tool" role; fold the result back in
+			// as a user turn so the model still sees it.
+			role = "user"
+		}
+		out = append(out, ollamaMessage{Role: role, Content: m.Content})
+	}
+	return out
+}
+
+func toOllamaTools(tools []toolbox.ToolSpec) []ollamaTool {
+	if len(tools) == 0 {
+		return nil
+	}
+	out := make([]ollamaTool, 0, len(tools))
+	for _, t := range tools {
+		out = append(out, ollamaTool{
+			Type: "function",
+			Function: ollamaToolFunction{
+				Name:        t.Name,
+				Description: t.Description,
+				Parameters:  t.Parameters,
+			},
+		})
+	}
+	return out
+}
+
+func fromOllamaToolCalls(calls []ollamaToolCall) []ToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	out := make([]ToolCall, 0, len(calls))
+	for _, c := range calls {
+		out = append(out, ToolCall{Name: c.Function.Name, Arguments: string(c.Function.Arguments)})
+	}
+	return out
+}
@@ -0,0 +1,179 @@
+// Package provider abstracts chat-completion backends behind a single
+// interface, so that lambdachat can talk to Lambda Chat, Ollama, Anthropic,
+// or Google Gemini without the rest of the code depending on any one of
+// their wire formats. Each adapter is responsible for translating its
+// provider's message/tool format to and from the provider-agnostic types
+// defined here.
+package provider
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/lambda/lambdachat-slackbot/internal/agent/toolbox"
+)
+
+// Role values for Message.Role.
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleTool      = "tool"
+)
+
+// Message is a single turn in a conversation, in a form every provider
+// adapter can translate to and from its own wire format.
+type Message struct {
+	// Role is one of "system", "user", "assistant", or "tool".
+	Role string
+
+	Content string
+
+	// ToolCalls is set on an assistant message that requested tool calls.
+	ToolCalls []ToolCall
+
+	// ToolCallID and Name identify which call a "tool" role message is
+	// responding to.
+	ToolCallID string
+	Name       string
+}
+
+// ToolCall is a single invocation of a tool requested by the model.
+type ToolCall struct {
+	ID   string
+	Name string
+
+	// Arguments is the tool's arguments encoded as a JSON object.
+	Arguments string
+}
+
+// Request is a provider-agnostic chat completion request. Temperature, TopP,
+// and MaxTokens are nil when the model config didn't set a default, in which
+// case an adapter should omit them and let the provider apply its own
+// default rather than sending an explicit zero value.
+type Request struct {
+	Model    string
+	Messages []Message
+	Tools    []toolbox.ToolSpec
+
+	Temperature *float64
+	TopP        *float64
+	MaxTokens   *int
+}
+
+// Reply is the result of a chat completion call. ToolCalls is non-empty when
+// the model asked to invoke tools instead of (or alongside) replying.
+type Reply struct {
+	Content   string
+	ToolCalls []ToolCall
+}
+
+// ChatCompletionProvider is a chat-completion backend. Implementations own
+// translating Request/Reply to and from their provider's wire format.
+type ChatCompletionProvider interface {
+	// Name identifies the provider, e.g. for modelInfo.Provider routing.
+	Name() string
+
+	// CreateChatCompletion sends req and returns the model's full reply.
+	CreateChatCompletion(ctx context.Context, req Request) (Reply, error)
+
+	// CreateChatCompletionStream sends req and streams content tokens to w
+	// as they arrive. Any tool calls requested by the model are returned in
+	// the final Reply rather than being streamed.
+	CreateChatCompletionStream(ctx context.Context, req Request, w io.Writer) (Reply, error)
+}
+
+// ModelLister is implemented by providers that can enumerate the models
+// their backend currently serves. Not every provider supports this.
+type ModelLister interface {
+	ListModels(ctx context.Context) ([]string, error)
+}
+
+// StatusError is implemented by an error that carries the HTTP status code
+// the backend responded with, so a caller like lambdachat's fallback routing
+// can tell a transient failure (5xx, 429) from a permanent one (4xx) without
+// depending on any one provider's error types.
+type StatusError interface {
+	error
+	StatusCode() int
+}
+
+// statusError is the concrete StatusError every adapter wraps its backend's
+// HTTP errors in.
+type statusError struct {
+	code int
+	err  error
+}
+
+// NewStatusError wraps err as a StatusError reporting code, for adapters
+// that parse an HTTP status out of their backend client's error.
+func NewStatusError(code int, err error) error {
+	return &statusError{code: code, err: err}
+}
+
+func (e *statusError) StatusCode() int { return e.code }
+func (e *statusError) Error() string   { return e.err.Error() }
+func (e *statusError) Unwrap() error   { return e.err }
+
+// DetectFromURL guesses a provider name from a backend's base URL, so New
+// can pick a sensible default adapter when one isn't specified explicitly.
+func DetectFromURL(url string) string {
+	lower := strings.ToLower(url)
+	switch {
+	case strings.Contains(lower, "anthropic.com"):
+		return NameAnthropic
+	case strings.Contains(lower, "generativelanguage.googleapis.com"):
+		return NameGoogle
+	case strings.Contains(lower, ":11434"), strings.Contains(lower, "/api/chat"):
+		return NameOllama
+	default:
+		return NameLambda
+	}
+}
+
+// Provider names, used as modelInfo.Provider values and as the keys under
+// which adapters are registered.
+const (
+	NameLambda    = "lambda"
+	NameOllama    = "ollama"
+	NameAnthropic = "anthropic"
+	NameGoogle    = "google"
+)
+
+// headerTransport adds a fixed set of headers (e.g. a reverse proxy's auth
+// token) to every outgoing request before delegating to base.
+type headerTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// withHeaders wraps base so every request carries headers, or returns base
+// unchanged if headers is empty.
+func withHeaders(base http.RoundTripper, headers map[string]string) http.RoundTripper {
+	if len(headers) == 0 {
+		return base
+	}
+	return &headerTransport{base: base, headers: headers}
+}
+
+// splitSystemPrompt pulls the leading system message (if any) out of
+// messages, since Anthropic and Google both take the system prompt as a
+// separate field rather than as a message with role "system".
+func splitSystemPrompt(messages []Message) (system string, rest []Message) {
+	rest = messages
+	if len(messages) > 0 && messages[0].Role == "system" {
+		system = messages[0].Content
+		rest = messages[1:]
+	}
+	return system, rest
+}
@@ -0,0 +1,163 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewStatusError(t *testing.T) {
+	err := NewStatusError(503, errors.New("backend unavailable"))
+
+	var se StatusError
+	if !errors.As(err, &se) {
+		t.Fatalf("errors.As failed to extract a StatusError from %v", err)
+	}
+	if se.StatusCode() != 503 {
+		t.Errorf("StatusCode() = %d, want 503", se.StatusCode())
+	}
+	if !strings.Contains(err.Error(), "backend unavailable") {
+		t.Errorf("Error() = %q, want it to contain the wrapped message", err.Error())
+	}
+}
+
+func TestAnthropicCreateChatCompletion(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("x-api-key"); got != "test-key" {
+			t.Errorf("x-api-key header = %q, want %q", got, "test-key")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"content":[{"type":"text","text":"hi there"}]}`))
+	}))
+	defer srv.Close()
+
+	p := NewAnthropic(srv.URL, "test-key", nil)
+	reply, err := p.CreateChatCompletion(context.Background(), Request{
+		Model:    "claude-3",
+		Messages: []Message{{Role: RoleUser, Content: "hello"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletion failed: %v", err)
+	}
+	if reply.Content != "hi there" {
+		t.Errorf("Content = %q, want %q", reply.Content, "hi there")
+	}
+}
+
+func TestAnthropicStatusErrorWrapping(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"bad request"}`))
+	}))
+	defer srv.Close()
+
+	p := NewAnthropic(srv.URL, "test-key", nil)
+	_, err := p.CreateChatCompletion(context.Background(), Request{Model: "claude-3"})
+	if err == nil {
+		t.Fatalf("Expected an error for a 400 response")
+	}
+
+	var se StatusError
+	if !errors.As(err, &se) {
+		t.Fatalf("Expected a 400 response to be wrapped as a StatusError, got %v", err)
+	}
+	if se.StatusCode() != http.StatusBadRequest {
+		t.Errorf("StatusCode() = %d, want %d", se.StatusCode(), http.StatusBadRequest)
+	}
+}
+
+func TestGoogleCreateChatCompletion(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"hi there"}]}}]}`))
+	}))
+	defer srv.Close()
+
+	p := NewGoogle(srv.URL, "test-key", nil)
+	reply, err := p.CreateChatCompletion(context.Background(), Request{
+		Model:    "gemini-pro",
+		Messages: []Message{{Role: RoleUser, Content: "hello"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletion failed: %v", err)
+	}
+	if reply.Content != "hi there" {
+		t.Errorf("Content = %q, want %q", reply.Content, "hi there")
+	}
+}
+
+func TestGoogleStatusErrorWrapping(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error":"rate limited"}`))
+	}))
+	defer srv.Close()
+
+	p := NewGoogle(srv.URL, "test-key", nil)
+	_, err := p.CreateChatCompletion(context.Background(), Request{Model: "gemini-pro"})
+
+	var se StatusError
+	if !errors.As(err, &se) || se.StatusCode() != http.StatusTooManyRequests {
+		t.Fatalf("Expected a 429 response wrapped as a StatusError, got %v", err)
+	}
+}
+
+func TestOllamaCreateChatCompletion(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"message":{"role":"assistant","content":"hi there"},"done":true}`))
+	}))
+	defer srv.Close()
+
+	p := NewOllama(srv.URL, nil)
+	reply, err := p.CreateChatCompletion(context.Background(), Request{
+		Model:    "llama3",
+		Messages: []Message{{Role: RoleUser, Content: "hello"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletion failed: %v", err)
+	}
+	if reply.Content != "hi there" {
+		t.Errorf("Content = %q, want %q", reply.Content, "hi there")
+	}
+}
+
+func TestOllamaCreateChatCompletionStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"message":{"role":"assistant","content":"hi "},"done":false}` + "\n"))
+		_, _ = w.Write([]byte(`{"message":{"role":"assistant","content":"there"},"done":true}` + "\n"))
+	}))
+	defer srv.Close()
+
+	p := NewOllama(srv.URL, nil)
+	var out strings.Builder
+	reply, err := p.CreateChatCompletionStream(context.Background(), Request{
+		Model:    "llama3",
+		Messages: []Message{{Role: RoleUser, Content: "hello"}},
+	}, &out)
+	if err != nil {
+		t.Fatalf("CreateChatCompletionStream failed: %v", err)
+	}
+	if reply.Content != "hi there" || out.String() != "hi there" {
+		t.Errorf("Content = %q, streamed = %q, want both %q", reply.Content, out.String(), "hi there")
+	}
+}
+
+func TestOllamaStatusErrorWrapping(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("internal error"))
+	}))
+	defer srv.Close()
+
+	p := NewOllama(srv.URL, nil)
+	_, err := p.CreateChatCompletion(context.Background(), Request{Model: "llama3"})
+
+	var se StatusError
+	if !errors.As(err, &se) || se.StatusCode() != http.StatusInternalServerError {
+		t.Fatalf("Expected a 500 response wrapped as a StatusError, got %v", err)
+	}
+}
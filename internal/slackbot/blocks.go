@@ -0,0 +1,146 @@
+package slackbot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+// maxSectionTextLen is Slack's limit on a section block's text object (3000
+// characters); a prose paragraph longer than this is split across multiple
+// section blocks instead of being truncated.
+const maxSectionTextLen = 3000
+
+// replyBlocks renders a completed assistant reply as Block Kit: a badge
+// section showing the active model and persona, one section block per
+// prose paragraph, a rich_text_preformatted block per fenced code segment,
+// and a trailing actions block for regenerating or changing settings.
+func replyBlocks(content, model, persona string) []slack.Block {
+	blocks := []slack.Block{badgeBlock(model, persona)}
+
+	for _, seg := range splitCodeFences(content) {
+		if seg.code {
+			blocks = append(blocks, codeFenceBlock(seg.text, seg.lang))
+			continue
+		}
+		blocks = append(blocks, proseBlocks(seg.text)...)
+	}
+
+	return append(blocks, replyActionsBlock())
+}
+
+// badgeBlock renders a small section noting the model and persona that
+// produced the reply.
+func badgeBlock(model, persona string) slack.Block {
+	return slack.NewSectionBlock(
+		slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf(":brain: *%s*  ·  :performing_arts: *%s*", model, persona), false, false),
+		nil, nil,
+	)
+}
+
+// proseBlocks renders a paragraph of plain text as one or more section
+// blocks, splitting on maxSectionTextLen instead of truncating.
+func proseBlocks(text string) []slack.Block {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+
+	var blocks []slack.Block
+	for len(text) > maxSectionTextLen {
+		cut := strings.LastIndex(text[:maxSectionTextLen], "\n")
+		if cut <= 0 {
+			cut = maxSectionTextLen
+		}
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, text[:cut], false, false), nil, nil,
+		))
+		text = strings.TrimSpace(text[cut:])
+	}
+	return append(blocks, slack.NewSectionBlock(
+		slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil,
+	))
+}
+
+// codeFenceBlock renders a fenced code segment as a rich_text_preformatted
+// block. Slack's rich_text_preformatted has no field for a language hint, so
+// one is surfaced as a leading comment line instead of being dropped.
+func codeFenceBlock(code, lang string) slack.Block {
+	text := code
+	if lang != "" {
+		text = fmt.Sprintf("# %s\n%s", lang, code)
+	}
+
+	return slack.NewRichTextBlock("",
+		&slack.RichTextPreformatted{
+			RichTextSection: slack.RichTextSection{
+				Type:     slack.RTEPreformatted,
+				Elements: []slack.RichTextSectionElement{slack.NewRichTextSectionTextElement(text, nil)},
+			},
+		},
+	)
+}
+
+// replyActionsBlock renders the "Regenerate"/"Reset"/"Change persona"/
+// "Change model" buttons attached to every rendered reply.
+func replyActionsBlock() slack.Block {
+	return slack.NewActionBlock("reply_actions",
+		slack.NewButtonBlockElement("regenerate", "regenerate", slack.NewTextBlockObject(slack.PlainTextType, "🔄 Regenerate", true, false)),
+		slack.NewButtonBlockElement("reset_conversation", "reset_conversation", slack.NewTextBlockObject(slack.PlainTextType, "🧹 Reset", true, false)),
+		slack.NewButtonBlockElement("show_personas", "show_personas", slack.NewTextBlockObject(slack.PlainTextType, "🎭 Change persona", true, false)),
+		slack.NewButtonBlockElement("show_models", "show_models", slack.NewTextBlockObject(slack.PlainTextType, "🧠 Change model", true, false)),
+	)
+}
+
+// codeSegment is one piece of a reply split by splitCodeFences: either a
+// prose paragraph (code == false) or a fenced code block (code == true,
+// with lang holding the fence's language hint, or "" if none was given).
+type codeSegment struct {
+	text string
+	lang string
+	code bool
+}
+
+// splitCodeFences splits content on ``` fences into alternating prose and
+// code segments, in order. An unterminated trailing fence is treated as
+// code through the end of the content rather than being discarded.
+func splitCodeFences(content string) []codeSegment {
+	var segments []codeSegment
+	rest := content
+	for {
+		start := strings.Index(rest, "```")
+		if start == -1 {
+			segments = append(segments, codeSegment{text: rest})
+			break
+		}
+
+		if prose := rest[:start]; strings.TrimSpace(prose) != "" {
+			segments = append(segments, codeSegment{text: prose})
+		}
+
+		rest = rest[start+3:]
+		lang, body, ok := splitFenceHeader(rest)
+		end := strings.Index(body, "```")
+		if !ok || end == -1 {
+			segments = append(segments, codeSegment{text: strings.TrimRight(body, "\n"), code: true, lang: lang})
+			break
+		}
+
+		segments = append(segments, codeSegment{text: strings.Trim(body[:end], "\n"), code: true, lang: lang})
+		rest = body[end+3:]
+	}
+	return segments
+}
+
+// splitFenceHeader splits the text immediately after an opening ``` into its
+// optional language hint (e.g. "go" in "```go\nfunc...") and the remaining
+// body. ok is false if rest ended before a newline was found, meaning there
+// is no body to speak of.
+func splitFenceHeader(rest string) (lang, body string, ok bool) {
+	nl := strings.Index(rest, "\n")
+	if nl == -1 {
+		return "", rest, false
+	}
+	return strings.TrimSpace(rest[:nl]), rest[nl+1:], true
+}
@@ -0,0 +1,84 @@
+package slackbot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func TestReplyBlocksRendersFencedCode(t *testing.T) {
+	content := "Here's a function:\n\n```go\nfunc add(a, b int) int {\n\treturn a + b\n}\n```\n\nThat's it."
+
+	blocks := replyBlocks(content, "gpt-test", "Bender")
+
+	if len(blocks) != 5 {
+		t.Fatalf("expected 5 blocks (badge, prose, code, prose, actions), got %d: %#v", len(blocks), blocks)
+	}
+
+	badge, ok := blocks[0].(*slack.SectionBlock)
+	if !ok {
+		t.Fatalf("expected blocks[0] to be a section block, got %T", blocks[0])
+	}
+	if !strings.Contains(badge.Text.Text, "gpt-test") || !strings.Contains(badge.Text.Text, "Bender") {
+		t.Errorf("badge block %q missing model or persona", badge.Text.Text)
+	}
+
+	prose, ok := blocks[1].(*slack.SectionBlock)
+	if !ok {
+		t.Fatalf("expected blocks[1] to be a section block, got %T", blocks[1])
+	}
+	if !strings.Contains(prose.Text.Text, "Here's a function:") {
+		t.Errorf("prose block %q missing leading paragraph", prose.Text.Text)
+	}
+
+	code, ok := blocks[2].(*slack.RichTextBlock)
+	if !ok {
+		t.Fatalf("expected blocks[2] to be a rich text block, got %T", blocks[2])
+	}
+	if len(code.Elements) != 1 {
+		t.Fatalf("expected 1 rich text element, got %d", len(code.Elements))
+	}
+	pre, ok := code.Elements[0].(*slack.RichTextPreformatted)
+	if !ok {
+		t.Fatalf("expected a rich_text_preformatted element, got %T", code.Elements[0])
+	}
+	if pre.Type != slack.RTEPreformatted {
+		t.Errorf("expected Type %q, got %q", slack.RTEPreformatted, pre.Type)
+	}
+	text, ok := pre.Elements[0].(*slack.RichTextSectionTextElement)
+	if !ok {
+		t.Fatalf("expected a rich text section text element, got %T", pre.Elements[0])
+	}
+	if !strings.Contains(text.Text, "func add(a, b int) int") {
+		t.Errorf("code block %q missing source", text.Text)
+	}
+	if !strings.Contains(text.Text, "go") {
+		t.Errorf("code block %q missing language hint", text.Text)
+	}
+
+	actions, ok := blocks[4].(*slack.ActionBlock)
+	if !ok {
+		t.Fatalf("expected blocks[4] to be an actions block, got %T", blocks[4])
+	}
+	if len(actions.Elements.ElementSet) != 4 {
+		t.Errorf("expected 4 action buttons, got %d", len(actions.Elements.ElementSet))
+	}
+}
+
+func TestSplitCodeFencesRoundTripsProseAndCode(t *testing.T) {
+	segments := splitCodeFences("before\n```py\nprint(1)\n```\nafter")
+
+	if len(segments) != 3 {
+		t.Fatalf("expected 3 segments, got %d: %#v", len(segments), segments)
+	}
+	if segments[0].code || segments[0].text != "before\n" {
+		t.Errorf("unexpected prose segment: %#v", segments[0])
+	}
+	if !segments[1].code || segments[1].lang != "py" || segments[1].text != "print(1)" {
+		t.Errorf("unexpected code segment: %#v", segments[1])
+	}
+	if segments[2].code || segments[2].text != "\nafter" {
+		t.Errorf("unexpected trailing prose segment: %#v", segments[2])
+	}
+}
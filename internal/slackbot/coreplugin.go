@@ -0,0 +1,333 @@
+package slackbot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lambda/lambdachat-slackbot/internal/lambdachat"
+	"github.com/slack-go/slack"
+)
+
+// corePlugin provides the bot's original built-in slash commands (/reset,
+// /persona(s), /model(s)) as an ordinary Plugin, so they can be dropped or
+// replaced the same way a third-party plugin would be.
+type corePlugin struct{}
+
+// NewCorePlugin returns the Plugin backing the bot's built-in commands.
+func NewCorePlugin() Plugin {
+	return corePlugin{}
+}
+
+// HearActions implements Plugin.
+func (corePlugin) HearActions() []HearAction { return nil }
+
+// Commands implements Plugin.
+func (corePlugin) Commands() []Command {
+	return []Command{
+		{Name: "reset", Help: "Reset your conversation.", Handler: handleResetCommand},
+		{Name: "persona", Help: "Set your persona, or list available personas if no name is given.", Handler: handlePersonaCommand},
+		{Name: "personas", Help: "List available personas.", Handler: handlePersonasCommand},
+		{Name: "agent", Help: "Bind an agent (a persona with tool access), or list available agents if no name is given.", Handler: handleAgentCommand},
+		{Name: "model", Help: "Set your model, or list available models if no name is given.", Handler: handleModelCommand},
+		{Name: "models", Help: "List available models.", Handler: handleModelsCommand},
+		{Name: "health", Help: "Show each model's fallback-routing health.", Handler: handleHealthCommand},
+		{Name: "conversations", Help: "List your conversations.", Handler: handleConversationsCommand},
+		{Name: "resume", Help: "Resume a conversation: /resume <id>.", Handler: handleResumeCommand},
+		{Name: "rename", Help: "Rename a conversation: /rename <id> <title>.", Handler: handleRenameCommand},
+		{Name: "delete", Help: "Delete a conversation: /delete <id>.", Handler: handleDeleteCommand},
+	}
+}
+
+func handleResetCommand(ctx *Context, cmd slack.SlashCommand) error {
+	_ = ctx.LambdaChat().Reset(fmt.Sprintf("slack-user-%s", cmd.UserID))
+	ctx.ResetThread(cmd.ChannelID, cmd.UserID)
+
+	return postEphemeral(ctx, cmd.ChannelID, cmd.UserID, "*Conversation has been reset.*")
+}
+
+func handlePersonaCommand(ctx *Context, cmd slack.SlashCommand) error {
+	if cmd.Text == "" {
+		return postPersonas(ctx, cmd.ChannelID, cmd.UserID)
+	}
+
+	userID := fmt.Sprintf("slack-user-%s", cmd.UserID)
+	response, err := ctx.LambdaChat().SetPersona(userID, cmd.Text)
+	if err != nil {
+		errorMsg := fmt.Sprintf("Error setting persona: %v", err)
+		if postErr := postBlocks(ctx, cmd.ChannelID, cmd.UserID, errorMsg, personaBlocks(errorMsg, ctx.LambdaChat().PersonaInfo())); postErr != nil {
+			return postErr
+		}
+		return err
+	}
+
+	return postEphemeral(ctx, cmd.ChannelID, cmd.UserID, response)
+}
+
+func handlePersonasCommand(ctx *Context, cmd slack.SlashCommand) error {
+	return postPersonas(ctx, cmd.ChannelID, cmd.UserID)
+}
+
+func postPersonas(ctx *Context, channel, user string) error {
+	personas := ctx.LambdaChat().PersonaInfo()
+	return postBlocks(ctx, channel, user, "Available personas", personaBlocks("*Available personas:*", personas))
+}
+
+// personaBlocks renders a "Personas" header block, then header as a section
+// block, followed by a section block per persona (name in bold plus its description) and an actions block with
+// a "set_persona:<name>" button per persona.
+func personaBlocks(header string, personas []lambdachat.PersonaSummary) []slack.Block {
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, "Personas", false, false)),
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, header, false, false), nil, nil),
+	}
+
+	var buttons []slack.BlockElement
+	for _, persona := range personas {
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*%s* - %s", persona.Name, persona.Description), false, false),
+			nil, nil,
+		))
+		buttons = append(buttons, slack.NewButtonBlockElement(
+			fmt.Sprintf("set_persona:%s", persona.Name), persona.Name,
+			slack.NewTextBlockObject(slack.PlainTextType, persona.Name, false, false),
+		))
+	}
+	if len(buttons) > 0 {
+		blocks = append(blocks, slack.NewActionBlock("persona_actions", buttons...))
+	}
+	return blocks
+}
+
+func handleAgentCommand(ctx *Context, cmd slack.SlashCommand) error {
+	if cmd.Text == "" {
+		return postAgents(ctx, cmd.ChannelID, cmd.UserID)
+	}
+
+	userID := fmt.Sprintf("slack-user-%s", cmd.UserID)
+	response, err := ctx.LambdaChat().SetPersona(userID, cmd.Text)
+	if err != nil {
+		errorMsg := fmt.Sprintf("Error setting agent: %v", err)
+		if postErr := postBlocks(ctx, cmd.ChannelID, cmd.UserID, errorMsg, agentBlocks(errorMsg, ctx.LambdaChat().AgentInfo())); postErr != nil {
+			return postErr
+		}
+		return err
+	}
+
+	return postEphemeral(ctx, cmd.ChannelID, cmd.UserID, response)
+}
+
+func postAgents(ctx *Context, channel, user string) error {
+	agents := ctx.LambdaChat().AgentInfo()
+	return postBlocks(ctx, channel, user, "Available agents", agentBlocks("*Available agents:*", agents))
+}
+
+// agentBlocks renders an "Agents" header block, then header as a section
+// block, followed by a section block per agent (name in bold plus the tools it can call) and an actions block
+// with a "set_persona:<name>" button per agent, reusing the same action ID
+// as a persona: SetPersona binds an agent whenever the name matches one.
+func agentBlocks(header string, agents []lambdachat.AgentSummary) []slack.Block {
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, "Agents", false, false)),
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, header, false, false), nil, nil),
+	}
+
+	var buttons []slack.BlockElement
+	for _, ag := range agents {
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*%s* - tools: %s", ag.Name, strings.Join(ag.ToolNames, ", ")), false, false),
+			nil, nil,
+		))
+		buttons = append(buttons, slack.NewButtonBlockElement(
+			fmt.Sprintf("set_persona:%s", ag.Name), ag.Name,
+			slack.NewTextBlockObject(slack.PlainTextType, ag.Name, false, false),
+		))
+	}
+	if len(buttons) > 0 {
+		blocks = append(blocks, slack.NewActionBlock("agent_actions", buttons...))
+	}
+	return blocks
+}
+
+func handleModelsCommand(ctx *Context, cmd slack.SlashCommand) error {
+	return postModels(ctx, cmd.ChannelID, cmd.UserID)
+}
+
+func postModels(ctx *Context, channel, user string) error {
+	models := ctx.LambdaChat().ModelInfo()
+	return postBlocks(ctx, channel, user, "Available models", modelBlocks("*Available models:*", models))
+}
+
+// modelBlocks renders a "Models" header block, then header as a section
+// block, followed by a section block per model (name in bold, display name, and context length) and an actions
+// block with a "set_model:<name>" button per model.
+func modelBlocks(header string, models []lambdachat.ModelSummary) []slack.Block {
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, "Models", false, false)),
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, header, false, false), nil, nil),
+	}
+
+	var buttons []slack.BlockElement
+	for _, model := range models {
+		text := fmt.Sprintf("*%s* - %s", model.Name, model.Description)
+		if model.ContextLength > 0 {
+			text = fmt.Sprintf("%s (%d token context)", text, model.ContextLength)
+		}
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, text, false, false),
+			nil, nil,
+		))
+		buttons = append(buttons, slack.NewButtonBlockElement(
+			fmt.Sprintf("set_model:%s", model.Name), model.Name,
+			slack.NewTextBlockObject(slack.PlainTextType, model.Name, false, false),
+		))
+	}
+	if len(buttons) > 0 {
+		blocks = append(blocks, slack.NewActionBlock("model_actions", buttons...))
+	}
+	return blocks
+}
+
+func handleModelCommand(ctx *Context, cmd slack.SlashCommand) error {
+	if cmd.Text == "" {
+		return postModels(ctx, cmd.ChannelID, cmd.UserID)
+	}
+
+	userID := fmt.Sprintf("slack-user-%s", cmd.UserID)
+	stripped := strings.Replace(cmd.Text, "*", "", -1)
+	response, err := ctx.LambdaChat().SetModel(userID, stripped)
+	if err != nil {
+		errorMsg := fmt.Sprintf("Error setting model: %v", err)
+		if postErr := postBlocks(ctx, cmd.ChannelID, cmd.UserID, errorMsg, modelBlocks(errorMsg, ctx.LambdaChat().ModelInfo())); postErr != nil {
+			return postErr
+		}
+		return err
+	}
+
+	_, _, err = ctx.PostMessage(cmd.ChannelID, slack.MsgOptionText(response, false))
+	return err
+}
+
+func handleHealthCommand(ctx *Context, cmd slack.SlashCommand) error {
+	health := ctx.LambdaChat().HealthInfo()
+	return postBlocks(ctx, cmd.ChannelID, cmd.UserID, "Model health", healthBlocks(health))
+}
+
+func handleConversationsCommand(ctx *Context, cmd slack.SlashCommand) error {
+	userID := fmt.Sprintf("slack-user-%s", cmd.UserID)
+	convos, err := ctx.LambdaChat().ListConversations(userID)
+	if err != nil {
+		return postEphemeral(ctx, cmd.ChannelID, cmd.UserID, fmt.Sprintf("Error listing conversations: %v", err))
+	}
+	if len(convos) == 0 {
+		return postEphemeral(ctx, cmd.ChannelID, cmd.UserID, "You have no conversations yet.")
+	}
+
+	var sb strings.Builder
+	sb.WriteString("*Your conversations:*\n")
+	for _, c := range convos {
+		fmt.Fprintf(&sb, "- `%s`: %s (updated %s)\n", c.ID, c.Title, c.UpdatedAt.Format("2006-01-02 15:04"))
+	}
+	return postEphemeral(ctx, cmd.ChannelID, cmd.UserID, sb.String())
+}
+
+func handleResumeCommand(ctx *Context, cmd slack.SlashCommand) error {
+	if cmd.Text == "" {
+		return postEphemeral(ctx, cmd.ChannelID, cmd.UserID, "Usage: /resume <id>")
+	}
+
+	userID := fmt.Sprintf("slack-user-%s", cmd.UserID)
+	if err := ctx.LambdaChat().SwitchConversation(userID, cmd.Text); err != nil {
+		return postEphemeral(ctx, cmd.ChannelID, cmd.UserID, fmt.Sprintf("Error resuming conversation: %v", err))
+	}
+	return postEphemeral(ctx, cmd.ChannelID, cmd.UserID, fmt.Sprintf("Resumed conversation %s.", cmd.Text))
+}
+
+func handleRenameCommand(ctx *Context, cmd slack.SlashCommand) error {
+	id, newTitle, ok := splitFirstField(cmd.Text)
+	if !ok {
+		return postEphemeral(ctx, cmd.ChannelID, cmd.UserID, "Usage: /rename <id> <title>")
+	}
+
+	userID := fmt.Sprintf("slack-user-%s", cmd.UserID)
+	if err := ctx.LambdaChat().RenameConversation(userID, id, newTitle); err != nil {
+		return postEphemeral(ctx, cmd.ChannelID, cmd.UserID, fmt.Sprintf("Error renaming conversation: %v", err))
+	}
+	return postEphemeral(ctx, cmd.ChannelID, cmd.UserID, fmt.Sprintf("Renamed conversation %s to %q.", id, newTitle))
+}
+
+func handleDeleteCommand(ctx *Context, cmd slack.SlashCommand) error {
+	if cmd.Text == "" {
+		return postEphemeral(ctx, cmd.ChannelID, cmd.UserID, "Usage: /delete <id>")
+	}
+
+	userID := fmt.Sprintf("slack-user-%s", cmd.UserID)
+	if err := ctx.LambdaChat().DeleteConversation(userID, cmd.Text); err != nil {
+		return postEphemeral(ctx, cmd.ChannelID, cmd.UserID, fmt.Sprintf("Error deleting conversation: %v", err))
+	}
+	return postEphemeral(ctx, cmd.ChannelID, cmd.UserID, fmt.Sprintf("Deleted conversation %s.", cmd.Text))
+}
+
+// splitFirstField splits text into its first whitespace-delimited field and
+// the remainder, used by /rename's "<id> <title>" syntax. ok is false if
+// text doesn't contain both.
+func splitFirstField(text string) (first, rest string, ok bool) {
+	fields := strings.SplitN(text, " ", 2)
+	if len(fields) != 2 || strings.TrimSpace(fields[1]) == "" {
+		return "", "", false
+	}
+	return fields[0], strings.TrimSpace(fields[1]), true
+}
+
+// healthBlocks renders a header followed by one section block per model,
+// showing its status, cooldown (if unhealthy), success rate, and last error.
+func healthBlocks(health []lambdachat.ModelHealthSummary) []slack.Block {
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, "Model health", false, false)),
+	}
+
+	for _, h := range health {
+		status := "healthy"
+		if !h.Healthy {
+			status = fmt.Sprintf("cooling down until %s", h.CooldownUntil.Format("15:04:05"))
+		}
+		text := fmt.Sprintf("*%s* - %s (success rate: %.0f%%)", h.Model, status, h.SuccessRate*100)
+		if h.LastError != "" {
+			text += fmt.Sprintf("\n_last error: %s_", h.LastError)
+		}
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, text, false, false),
+			nil, nil,
+		))
+	}
+	return blocks
+}
+
+// postEphemeral posts message visible only to user in channel, falling
+// back to a normal, channel-visible post if the ephemeral post fails.
+func postEphemeral(ctx *Context, channel, user, message string) error {
+	if _, _, err := ctx.PostMessage(
+		channel,
+		slack.MsgOptionText(message, false),
+		slack.MsgOptionPostEphemeral(user),
+	); err != nil {
+		_, _, err = ctx.PostMessage(channel, slack.MsgOptionText(message, false))
+		return err
+	}
+	return nil
+}
+
+// postBlocks posts blocks visible only to user in channel, with fallback
+// text for surfaces (e.g. notifications) that can't render blocks, falling
+// back to a normal, channel-visible post if the ephemeral post fails.
+func postBlocks(ctx *Context, channel, user, fallbackText string, blocks []slack.Block) error {
+	options := []slack.MsgOption{
+		slack.MsgOptionText(fallbackText, false),
+		slack.MsgOptionBlocks(blocks...),
+		slack.MsgOptionPostEphemeral(user),
+	}
+	if _, _, err := ctx.PostMessage(channel, options...); err != nil {
+		_, _, err = ctx.PostMessage(channel, slack.MsgOptionText(fallbackText, false), slack.MsgOptionBlocks(blocks...))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package slackbot
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies this package's tracer and meter to
+// whatever TracerProvider/MeterProvider the operator wires up, so an
+// OTLP or Prometheus backend can group its spans and metrics together.
+const instrumentationName = "github.com/lambda/lambdachat-slackbot/internal/slackbot"
+
+// telemetry bundles the tracer, meter, and metric instruments the bot
+// records against as it handles Slack events and drives lambdaChat.
+type telemetry struct {
+	tracer trace.Tracer
+
+	messagesReceived  metric.Int64Counter
+	messagesSent      metric.Int64Counter
+	errors            metric.Int64Counter
+	threadExpirations metric.Int64Counter
+	threadResets      metric.Int64Counter
+	llmLatency        metric.Float64Histogram
+}
+
+// newTelemetry builds a telemetry from tp/mp, falling back to the global
+// TracerProvider/MeterProvider when either is nil, so New can be called
+// without an operator having configured OpenTelemetry at all: the
+// instruments become no-ops until a real provider is registered globally.
+func newTelemetry(tp trace.TracerProvider, mp metric.MeterProvider) *telemetry {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	if mp == nil {
+		mp = otel.GetMeterProvider()
+	}
+
+	meter := mp.Meter(instrumentationName)
+	t := &telemetry{tracer: tp.Tracer(instrumentationName)}
+
+	var err error
+	if t.messagesReceived, err = meter.Int64Counter(
+		"lambdachat.slackbot.messages_received",
+		metric.WithDescription("Slack events handled (app mentions, thread messages, direct messages, slash commands)"),
+	); err != nil {
+		otel.Handle(err)
+	}
+	if t.messagesSent, err = meter.Int64Counter(
+		"lambdachat.slackbot.messages_sent",
+		metric.WithDescription("Replies posted or updated back to Slack"),
+	); err != nil {
+		otel.Handle(err)
+	}
+	if t.errors, err = meter.Int64Counter(
+		"lambdachat.slackbot.errors",
+		metric.WithDescription("Errors encountered while handling events, by kind"),
+	); err != nil {
+		otel.Handle(err)
+	}
+	if t.threadExpirations, err = meter.Int64Counter(
+		"lambdachat.slackbot.thread_expirations",
+		metric.WithDescription("Times an active thread was found expired and its context reloaded"),
+	); err != nil {
+		otel.Handle(err)
+	}
+	if t.threadResets, err = meter.Int64Counter(
+		"lambdachat.slackbot.thread_resets",
+		metric.WithDescription("Times a user's conversation was reset due to a thread change or edit"),
+	); err != nil {
+		otel.Handle(err)
+	}
+	if t.llmLatency, err = meter.Float64Histogram(
+		"lambdachat.slackbot.llm_latency",
+		metric.WithDescription("Latency of lambdaChat.Chat/ChatStream calls"),
+		metric.WithUnit("s"),
+	); err != nil {
+		otel.Handle(err)
+	}
+
+	return t
+}
+
+// hashUserID returns a short, non-reversible identifier for a Slack user
+// ID, so span/log attributes can correlate events from the same user
+// without recording their raw ID.
+func hashUserID(userID string) string {
+	sum := sha256.Sum256([]byte(userID))
+	return hex.EncodeToString(sum[:8])
+}
+
+// startEventSpan begins a span for one incoming Slack event (an app
+// mention, thread message, direct message, or slash command), tagged with
+// the attributes every handler knows up front. Callers that learn the
+// active model/persona afterward should add them with span.SetAttributes.
+func (t *telemetry) startEventSpan(ctx context.Context, name, eventType, channel, userID string) (context.Context, trace.Span) {
+	ctx, span := t.tracer.Start(ctx, name, trace.WithAttributes(
+		attribute.String("event_type", eventType),
+		attribute.String("channel", channel),
+		attribute.String("user_hash", hashUserID(userID)),
+	))
+	t.recordMessageReceived(ctx, eventType)
+	return ctx, span
+}
+
+// traceChat wraps a lambdaChat.Chat/ChatStream call in a child span and
+// records its latency, marking the span and the errors counter if fn
+// fails.
+func (t *telemetry) traceChat(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	ctx, span := t.tracer.Start(ctx, name)
+	defer span.End()
+
+	start := time.Now()
+	err := fn(ctx)
+	if t.llmLatency != nil {
+		t.llmLatency.Record(ctx, time.Since(start).Seconds())
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		t.recordError(ctx, "llm")
+	}
+	return err
+}
+
+// traceSlackCall wraps a Slack Web API call (chat.postMessage,
+// chat.update, chat.delete, ...) in a child span, recording messagesSent
+// on success and errors (kind "slack") on failure.
+func (t *telemetry) traceSlackCall(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	ctx, span := t.tracer.Start(ctx, name)
+	defer span.End()
+
+	err := fn(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		t.recordError(ctx, "slack")
+		return err
+	}
+	t.recordMessageSent(ctx)
+	return nil
+}
+
+func (t *telemetry) recordMessageReceived(ctx context.Context, eventType string) {
+	if t.messagesReceived == nil {
+		return
+	}
+	t.messagesReceived.Add(ctx, 1, metric.WithAttributes(attribute.String("event_type", eventType)))
+}
+
+func (t *telemetry) recordMessageSent(ctx context.Context) {
+	if t.messagesSent == nil {
+		return
+	}
+	t.messagesSent.Add(ctx, 1)
+}
+
+func (t *telemetry) recordError(ctx context.Context, kind string) {
+	if t.errors == nil {
+		return
+	}
+	t.errors.Add(ctx, 1, metric.WithAttributes(attribute.String("kind", kind)))
+}
+
+func (t *telemetry) recordThreadExpired(ctx context.Context) {
+	if t.threadExpirations == nil {
+		return
+	}
+	t.threadExpirations.Add(ctx, 1)
+}
+
+func (t *telemetry) recordThreadReset(ctx context.Context) {
+	if t.threadResets == nil {
+		return
+	}
+	t.threadResets.Add(ctx, 1)
+}
@@ -0,0 +1,96 @@
+package slackbot
+
+import (
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/lambda/lambdachat-slackbot/internal/lambdachat"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+// Command registers a slash command name (without the leading slash, e.g.
+// "reset" for "/reset") and its handler.
+type Command struct {
+	Name    string
+	Help    string
+	Handler func(ctx *Context, cmd slack.SlashCommand) error
+}
+
+// HearAction triggers Handler whenever a regular message's text matches
+// Matcher, before the message falls through to the configured
+// LambdaChatter. Handler writes its reply to w.
+type HearAction struct {
+	Matcher *regexp.Regexp
+	Handler func(ctx *Context, ev *slackevents.MessageEvent, w io.Writer) error
+}
+
+// Plugin bundles a set of slash commands and passive "hear actions" that
+// can be registered with a slackBot at construction time, so third parties
+// can extend the bot (a "!summarize last N", a karma tracker, a docs
+// lookup) without forking it.
+type Plugin interface {
+	Commands() []Command
+	HearActions() []HearAction
+}
+
+// Context gives a plugin access to the bot's capabilities without exposing
+// its internals.
+type Context struct {
+	bot *slackBot
+}
+
+// LambdaChat returns the LambdaChatter backing this bot, so a plugin can
+// drive conversations (Chat, Reset, SetPersona, ...) directly.
+func (c *Context) LambdaChat() lambdachat.LambdaChatter {
+	return c.bot.lambdaChat
+}
+
+// PostMessage posts a message to channel via chat.postMessage.
+func (c *Context) PostMessage(channel string, options ...slack.MsgOption) (string, string, error) {
+	return c.bot.client.Client.PostMessage(channel, options...)
+}
+
+// UpdateMessage updates an existing message in place via chat.update.
+func (c *Context) UpdateMessage(channel, ts string, options ...slack.MsgOption) (string, string, string, error) {
+	return c.bot.client.Client.UpdateMessage(channel, ts, options...)
+}
+
+// ResetThread clears any thread tracked for channel+user, e.g. after a
+// plugin resets the user's conversation.
+func (c *Context) ResetThread(channel, user string) {
+	c.bot.threadsMu.Lock()
+	delete(c.bot.activeThreads, threadKey(channel, user))
+	c.bot.threadsMu.Unlock()
+}
+
+// Log writes a line to the bot's WebUI, if one is configured.
+func (c *Context) Log(msg string) {
+	if c.bot.webUI != nil {
+		c.bot.webUI.Log(msg)
+	}
+}
+
+// registerPlugins builds the command and hear-action dispatch tables from
+// plugins. Later plugins win on a name collision.
+func (sb *slackBot) registerPlugins(plugins []Plugin) {
+	for _, p := range plugins {
+		for _, cmd := range p.Commands() {
+			sb.commands[strings.ToLower(cmd.Name)] = cmd
+		}
+		sb.hearActions = append(sb.hearActions, p.HearActions()...)
+	}
+}
+
+// dispatchHearActions runs ev's text against every registered HearAction in
+// registration order and invokes the first match's handler, reporting
+// whether anything matched.
+func (sb *slackBot) dispatchHearActions(ev *slackevents.MessageEvent, w io.Writer) (bool, error) {
+	for _, action := range sb.hearActions {
+		if action.Matcher.MatchString(ev.Text) {
+			return true, action.Handler(&Context{bot: sb}, ev, w)
+		}
+	}
+	return false, nil
+}
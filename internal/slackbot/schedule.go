@@ -0,0 +1,116 @@
+package slackbot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/robfig/cron/v3"
+)
+
+// ScheduleDefinition configures one recurring scheduled action, e.g. a
+// daily standup prompt or an end-of-day summary DM.
+type ScheduleDefinition struct {
+	// Cron is a standard 5-field cron expression, e.g. "0 9 * * 1-5" for
+	// weekdays at 09:00.
+	Cron string
+	// Channel is the Slack channel (or user ID, for a DM) the action
+	// posts into.
+	Channel string
+	// Action runs on each firing. It reports its result through post
+	// rather than returning content directly, so it can post incrementally
+	// (e.g. while streaming an LLM response) if it wants to.
+	Action func(ctx *Context, post func(content string) error) error
+}
+
+// StandupPrompt returns a ScheduleDefinition's Action that asks lambdaChat
+// to answer prompt and posts the reply, for the common case of "every
+// weekday at 09:00 post an LLM-generated standup prompt".
+func StandupPrompt(prompt string) func(ctx *Context, post func(content string) error) error {
+	return func(ctx *Context, post func(content string) error) error {
+		response, err := ctx.LambdaChat().Chat("scheduled-standup", prompt)
+		if err != nil {
+			return fmt.Errorf("generating standup prompt: %w", err)
+		}
+		return post(response)
+	}
+}
+
+// registerSchedules adds each definition's Cron expression to the bot's
+// cron runner. The runner itself is started and stopped by Run.
+func (sb *slackBot) registerSchedules(schedules []ScheduleDefinition) {
+	for _, sched := range schedules {
+		sched := sched
+		if _, err := sb.cronRunner.AddFunc(sched.Cron, func() {
+			sb.runSchedule(sched)
+		}); err != nil {
+			sb.l.Errorf("Failed to register scheduled action for channel %s (%s): %v", sched.Channel, sched.Cron, err)
+		}
+	}
+}
+
+// runSchedule executes a single scheduled action, posting its result
+// through the same streaming writer (and so the same webUI logging path)
+// as a normal reply.
+func (sb *slackBot) runSchedule(sched ScheduleDefinition) {
+	writer := sb.createChannelMessageWriter(sched.Channel)
+
+	post := func(content string) error {
+		_, err := writer.Write([]byte(content))
+		return err
+	}
+
+	if err := sched.Action(&Context{bot: sb}, post); err != nil {
+		sb.l.Errorf("Scheduled action for channel %s failed: %v", sched.Channel, err)
+		return
+	}
+
+	if w, ok := writer.(*multiWriter); ok {
+		if err := w.Flush(); err != nil {
+			sb.l.Errorf("Failed to flush scheduled post for channel %s: %v", sched.Channel, err)
+		}
+	}
+}
+
+// createChannelMessageWriter creates a writer that posts a scheduled
+// message directly into channel (not in reply to any particular message),
+// reusing the same streaming/rate-limiting and webUI-logging path as a
+// normal reply.
+func (sb *slackBot) createChannelMessageWriter(channel string) io.Writer {
+	const scheduledUser = "scheduled"
+
+	key := streamKey{channel: channel, user: scheduledUser}
+	state := &streamState{}
+	sb.streamCache.set(key, state)
+
+	return &multiWriter{
+		slackWriter: &threadMessageWriter{
+			bot:     sb,
+			ctx:     context.Background(),
+			channel: channel,
+			state:   state,
+		},
+		webUI:   sb.webUI,
+		user:    scheduledUser,
+		channel: channel,
+		content: new(strings.Builder),
+	}
+}
+
+// newCronRunner builds a cron.Cron using the bot's logger for its own
+// internal errors (e.g. a misbehaving job panicking).
+func newCronRunner(l interface{ Errorf(string, ...interface{}) }) *cron.Cron {
+	return cron.New(cron.WithChain(cron.Recover(cronLogger{l})))
+}
+
+// cronLogger adapts our logrus-shaped logger to cron's Logger interface.
+type cronLogger struct {
+	l interface{ Errorf(string, ...interface{}) }
+}
+
+func (c cronLogger) Info(msg string, keysAndValues ...interface{}) {}
+
+func (c cronLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	c.l.Errorf("%s: %v %v", msg, err, keysAndValues)
+}
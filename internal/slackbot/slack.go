@@ -1,6 +1,8 @@
 package slackbot
 
 import (
+	"container/list"
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -10,12 +12,25 @@ import (
 
 	"github.com/lambda/lambdachat-slackbot/internal/lambdachat"
 	"github.com/lambda/lambdachat-slackbot/internal/webui"
+	"github.com/robfig/cron/v3"
 	"github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
 	"github.com/slack-go/slack/socketmode"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// streamUpdateInterval bounds how often threadMessageWriter calls
+// chat.update while a response streams in, so a fast token stream doesn't
+// trip Slack's per-message rate limits.
+const streamUpdateInterval = 750 * time.Millisecond
+
+// streamCacheSize bounds how many in-flight/recent streamed replies are
+// remembered at once, across all channels and users.
+const streamCacheSize = 256
+
 // SlackBotter is the interface for interacting with the Slack API
 type SlackBotter interface {
 	// Run starts the Slack bot
@@ -29,17 +44,205 @@ type threadData struct {
 }
 
 type slackBot struct {
-	client         *socketmode.Client
-	lambdaChat     lambdachat.LambdaChatter
-	l              *logrus.Entry
-	messageMu      sync.Mutex
-	messageBuffers map[string]*strings.Builder
-	webUI          *webui.WebUI
+	client      *socketmode.Client
+	lambdaChat  lambdachat.LambdaChatter
+	l           *logrus.Entry
+	streamCache *streamLRU
+	webUI       *webui.WebUI
 	// Track active threads to handle continued conversation
 	threadsMu     sync.RWMutex
 	activeThreads map[string]threadData // Maps channel+user -> thread data
 	// Thread expiration time
 	threadExpiration time.Duration
+	// replyCache maps a triggering message to the bot's reply, so an edit
+	// or delete of the trigger can be mirrored onto that reply.
+	replyCache   *replyLRU
+	reactToEdits bool
+	// Plugin-registered slash commands (keyed by name, no leading slash)
+	// and passive hear actions, in registration order.
+	commands    map[string]Command
+	hearActions []HearAction
+	// cronRunner drives the bot's ScheduleDefinitions.
+	cronRunner *cron.Cron
+	// telemetry records spans and metrics for event handling and LLM calls.
+	telemetry *telemetry
+}
+
+// threadKey builds the map key used to track a user's active thread.
+func threadKey(channel, user string) string {
+	return fmt.Sprintf("%s-%s", channel, user)
+}
+
+// slackChannel namespaces a Slack channel ID for webui.Entry.Channel, which
+// now carries the transport name alongside the channel so a WebUI fed by
+// multiple transports (see internal/chat) can tell them apart.
+func slackChannel(channel string) string {
+	return "slack:" + channel
+}
+
+// streamKey identifies a single streamed reply, scoped to the channel+user
+// it's replying to so DMs, threads, and app-mention replies each track
+// their own placeholder message independently.
+type streamKey struct {
+	channel string
+	user    string
+}
+
+// streamState is the Slack-side state of one streamed reply: which message
+// to keep updating, and when it was last updated (for rate-limiting
+// chat.update calls).
+type streamState struct {
+	mu         sync.Mutex
+	parentTs   string // thread (or mention) timestamp the reply belongs to
+	responseTs string // timestamp of the placeholder message, once posted
+	lastUpdate time.Time
+}
+
+// streamLRU is a small size-bounded cache of streamState keyed by
+// streamKey, so a long-running bot doesn't accumulate unbounded per-user
+// state as channels and users come and go.
+type streamLRU struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[streamKey]*list.Element
+}
+
+type streamLRUEntry struct {
+	key   streamKey
+	state *streamState
+}
+
+func newStreamLRU(capacity int) *streamLRU {
+	return &streamLRU{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[streamKey]*list.Element),
+	}
+}
+
+// set records state as the current streamed reply for key, evicting the
+// least-recently-used entry if the cache is over capacity.
+func (c *streamLRU) set(key streamKey, state *streamState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.order.MoveToFront(el)
+		el.Value.(*streamLRUEntry).state = state
+		return
+	}
+
+	el := c.order.PushFront(&streamLRUEntry{key: key, state: state})
+	c.items[key] = el
+
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		if oldest != nil {
+			c.order.Remove(oldest)
+			delete(c.items, oldest.Value.(*streamLRUEntry).key)
+		}
+	}
+}
+
+// get returns the current streamed reply state for key, if any.
+func (c *streamLRU) get(key streamKey) (*streamState, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*streamLRUEntry).state, true
+}
+
+// messageRef identifies a single Slack message by channel and timestamp.
+type messageRef struct {
+	channel string
+	ts      string
+}
+
+// botResponse is the bot's reply to a triggering message, including the
+// thread (if any) it was posted in, so an edit or delete of the trigger
+// can find and update or delete the reply in turn.
+type botResponse struct {
+	channel  string
+	ts       string
+	threadTs string
+}
+
+// replyLRU is a small size-bounded cache mapping a triggering message to
+// the bot's reply to it.
+type replyLRU struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[messageRef]*list.Element
+}
+
+type replyLRUEntry struct {
+	key   messageRef
+	value botResponse
+}
+
+func newReplyLRU(capacity int) *replyLRU {
+	return &replyLRU{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[messageRef]*list.Element),
+	}
+}
+
+// set records value as the bot's reply to key, evicting the
+// least-recently-used entry if the cache is over capacity.
+func (c *replyLRU) set(key messageRef, value botResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.order.MoveToFront(el)
+		el.Value.(*replyLRUEntry).value = value
+		return
+	}
+
+	el := c.order.PushFront(&replyLRUEntry{key: key, value: value})
+	c.items[key] = el
+
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		if oldest != nil {
+			c.order.Remove(oldest)
+			delete(c.items, oldest.Value.(*replyLRUEntry).key)
+		}
+	}
+}
+
+// get returns the bot's reply to key, if any.
+func (c *replyLRU) get(key messageRef) (botResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return botResponse{}, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*replyLRUEntry).value, true
+}
+
+// delete evicts key from the cache, e.g. once its reply has been deleted.
+func (c *replyLRU) delete(key messageRef) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return
+	}
+	c.order.Remove(el)
+	delete(c.items, key)
 }
 
 type logger struct {
@@ -58,8 +261,18 @@ func (l logger) Output(i int, s string) error {
 	return nil
 }
 
-// New creates a new SlackBotter instance
-func New(l *logrus.Entry, appToken, botToken string, lambdaChat lambdachat.LambdaChatter, webUI *webui.WebUI) (SlackBotter, error) {
+// New creates a new SlackBotter instance. replyCacheSize bounds how many
+// triggering-message-to-reply mappings are remembered for edit/delete
+// handling; reactToEdits controls whether message_changed events cause the
+// bot to re-answer and update its existing reply. tp and mp are the
+// TracerProvider and MeterProvider event handling and LLM calls are
+// instrumented against; pass nil for either to use the global provider,
+// so operators can hook Prometheus or OTLP without code changes. schedules
+// are recurring actions (e.g. a daily standup prompt) run by a cron
+// scheduler alongside the event loop. plugins contribute slash commands
+// and passive hear actions; pass NewCorePlugin() to keep the built-in
+// /reset, /persona(s), and /model(s) commands.
+func New(l *logrus.Entry, appToken, botToken string, lambdaChat lambdachat.LambdaChatter, webUI *webui.WebUI, replyCacheSize int, reactToEdits bool, tp trace.TracerProvider, mp metric.MeterProvider, schedules []ScheduleDefinition, plugins ...Plugin) (SlackBotter, error) {
 	ll := logger{
 		l: l.WithField("slack-bot", "socketmode"),
 	}
@@ -77,19 +290,35 @@ func New(l *logrus.Entry, appToken, botToken string, lambdaChat lambdachat.Lambd
 		socketmode.OptionLog(ll),
 	)
 
-	return &slackBot{
+	sb := &slackBot{
 		client:           client,
 		lambdaChat:       lambdaChat,
 		l:                l,
-		messageBuffers:   make(map[string]*strings.Builder),
+		streamCache:      newStreamLRU(streamCacheSize),
 		webUI:            webUI,
 		activeThreads:    make(map[string]threadData),
 		threadExpiration: 1 * time.Hour, // Default expiration time: 1 hour
-	}, nil
+		replyCache:       newReplyLRU(replyCacheSize),
+		reactToEdits:     reactToEdits,
+		commands:         make(map[string]Command),
+		cronRunner:       newCronRunner(l),
+		telemetry:        newTelemetry(tp, mp),
+	}
+	sb.registerPlugins(plugins)
+	sb.registerSchedules(schedules)
+
+	return sb, nil
 }
 
-// Run starts the Slack bot
+// Run starts the Slack bot, along with its cron scheduler. The scheduler is
+// stopped cleanly (waiting for any in-flight scheduled action to finish)
+// once Run returns.
 func (sb *slackBot) Run() error {
+	sb.cronRunner.Start()
+	defer func() {
+		<-sb.cronRunner.Stop().Done()
+	}()
+
 	go sb.handleEvents()
 	return sb.client.Run()
 }
@@ -123,12 +352,19 @@ func (sb *slackBot) handleEvents() {
 					// Handle app mention events (when the bot is @mentioned)
 					go sb.handleAppMention(ev)
 				case *slackevents.MessageEvent:
-					// Check if message is in a thread we're tracking
-					if ev.ThreadTimeStamp != "" {
-						go sb.handleThreadMessage(ev)
-					} else if ev.ChannelType == "im" && ev.BotID == "" {
-						// Handle direct messages to the bot
-						go sb.handleDirectMessage(ev)
+					switch ev.SubType {
+					case "message_changed":
+						go sb.handleMessageChanged(ev)
+					case "message_deleted":
+						go sb.handleMessageDeleted(ev)
+					default:
+						// Check if message is in a thread we're tracking
+						if ev.ThreadTimeStamp != "" {
+							go sb.handleThreadMessage(ev)
+						} else if ev.ChannelType == "im" && ev.BotID == "" {
+							// Handle direct messages to the bot
+							go sb.handleDirectMessage(ev)
+						}
 					}
 				}
 			}
@@ -142,6 +378,8 @@ func (sb *slackBot) handleEvents() {
 			sb.l.Infof("Interaction received: %+v", callback)
 			sb.client.Ack(*evt.Request, nil)
 
+			go sb.handleInteraction(callback)
+
 		case socketmode.EventTypeSlashCommand:
 			cmd, ok := evt.Data.(slack.SlashCommand)
 			if !ok {
@@ -217,9 +455,12 @@ func (sb *slackBot) handleThreadMessage(ev *slackevents.MessageEvent) {
 	// Create a unique user ID using the Slack user ID
 	userID := fmt.Sprintf("slack-user-%s", ev.User)
 
+	ctx, span := sb.telemetry.startEventSpan(context.Background(), "slackbot.handleThreadMessage", "thread_message", ev.Channel, ev.User)
+	defer span.End()
+
 	// Log the user input to the web UI
 	if sb.webUI != nil {
-		sb.webUI.LogUserInput(ev.User, ev.Channel, ev.Text)
+		sb.webUI.LogUserInput(ev.User, slackChannel(ev.Channel), ev.Text)
 	}
 
 	// Check if we need to reload context from thread
@@ -234,6 +475,10 @@ func (sb *slackBot) handleThreadMessage(ev *slackevents.MessageEvent) {
 		// Reload context by resetting the user's context
 		_ = sb.lambdaChat.Reset(userID)
 		sb.l.Infof("Thread expired or changed, reloading context for user %s in channel %s", ev.User, ev.Channel)
+		if threadExpired {
+			sb.telemetry.recordThreadExpired(ctx)
+		}
+		sb.telemetry.recordThreadReset(ctx)
 
 		// Track this thread as the new active thread
 		sb.trackThread(ev.Channel, ev.User, ev.ThreadTimeStamp)
@@ -245,20 +490,46 @@ func (sb *slackBot) handleThreadMessage(ev *slackevents.MessageEvent) {
 		sb.trackThread(ev.Channel, ev.User, ev.ThreadTimeStamp)
 	}
 
+	span.SetAttributes(
+		attribute.String("model", sb.lambdaChat.CurrentModel(userID)),
+		attribute.String("persona", sb.lambdaChat.CurrentPersona(userID)),
+	)
+
 	// Create a message writer that will collect the Slack thread message
-	messageWriter := sb.createThreadMessageWriter(ev.Channel, ev.User, ev.ThreadTimeStamp)
+	messageWriter := sb.createThreadMessageWriter(ctx, ev.Channel, ev.User, ev.ThreadTimeStamp)
+
+	// Give registered hear actions first refusal on the message before it
+	// falls through to the LLM.
+	if matched, err := sb.dispatchHearActions(ev, messageWriter); matched {
+		if err != nil {
+			sb.l.Errorf("Hear action failed: %v", err)
+		}
+		if writer, ok := messageWriter.(*multiWriter); ok {
+			if err := writer.Flush(); err != nil {
+				sb.l.Errorf("Failed to flush hear-action response: %v", err)
+			}
+		}
+		sb.recordReply(ev.Channel, ev.User, ev.TimeStamp)
+		return
+	}
 
 	// Process the message and collect the response
-	err := sb.lambdaChat.ChatStream(userID, ev.Text, messageWriter)
+	err := sb.telemetry.traceChat(ctx, "lambdachat.ChatStream", func(ctx context.Context) error {
+		return sb.lambdaChat.ChatStream(userID, ev.Text, messageWriter)
+	})
 	if err != nil {
 		sb.l.Errorf("Failed to process thread message: %v", err)
-		_, _, err = sb.client.Client.PostMessage(
-			ev.Channel,
-			slack.MsgOptionText(fmt.Sprintf("Error: %v", err), false),
-			slack.MsgOptionTS(ev.ThreadTimeStamp),
-		)
-		if err != nil {
-			sb.l.Errorf("Failed to post error message: %v", err)
+		postErr := sb.telemetry.traceSlackCall(ctx, "slack.chat.postMessage", func(ctx context.Context) error {
+			_, _, err := sb.client.Client.PostMessageContext(
+				ctx,
+				ev.Channel,
+				slack.MsgOptionText(fmt.Sprintf("Error: %v", err), false),
+				slack.MsgOptionTS(ev.ThreadTimeStamp),
+			)
+			return err
+		})
+		if postErr != nil {
+			sb.l.Errorf("Failed to post error message: %v", postErr)
 		}
 		return
 	}
@@ -269,6 +540,8 @@ func (sb *slackBot) handleThreadMessage(ev *slackevents.MessageEvent) {
 			sb.l.Errorf("Failed to flush thread response: %v", err)
 		}
 	}
+
+	sb.recordReply(ev.Channel, ev.User, ev.TimeStamp)
 }
 
 // handleAppMention handles app mention events
@@ -279,9 +552,16 @@ func (sb *slackBot) handleAppMention(ev *slackevents.AppMentionEvent) {
 	// Create a unique user ID using the Slack user ID
 	userID := fmt.Sprintf("slack-user-%s", ev.User)
 
+	ctx, span := sb.telemetry.startEventSpan(context.Background(), "slackbot.handleAppMention", "app_mention", ev.Channel, ev.User)
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("model", sb.lambdaChat.CurrentModel(userID)),
+		attribute.String("persona", sb.lambdaChat.CurrentPersona(userID)),
+	)
+
 	// Log the user input to the web UI
 	if sb.webUI != nil {
-		sb.webUI.LogUserInput(ev.User, ev.Channel, text)
+		sb.webUI.LogUserInput(ev.User, slackChannel(ev.Channel), text)
 	}
 
 	// Determine thread timestamp to use:
@@ -302,6 +582,10 @@ func (sb *slackBot) handleAppMention(ev *slackevents.AppMentionEvent) {
 			// Reload context by resetting the user's context
 			_ = sb.lambdaChat.Reset(userID)
 			sb.l.Infof("Thread expired or changed, reloading context for user %s in channel %s", ev.User, ev.Channel)
+			if threadExpired {
+				sb.telemetry.recordThreadExpired(ctx)
+			}
+			sb.telemetry.recordThreadReset(ctx)
 		}
 
 		// Track or update this thread
@@ -316,19 +600,53 @@ func (sb *slackBot) handleAppMention(ev *slackevents.AppMentionEvent) {
 	}
 
 	// Create a message writer that will collect the Slack thread message
-	messageWriter := sb.createThreadMessageWriter(ev.Channel, ev.User, threadTs)
+	messageWriter := sb.createThreadMessageWriter(ctx, ev.Channel, ev.User, threadTs)
+
+	// Give registered hear actions first refusal on the message before it
+	// falls through to the LLM. Hear actions expect a slackevents.MessageEvent,
+	// so build one from the mention's fields.
+	mentionAsMessage := &slackevents.MessageEvent{
+		Type:            "message",
+		User:            ev.User,
+		Text:            text,
+		Channel:         ev.Channel,
+		ThreadTimeStamp: ev.ThreadTimeStamp,
+		TimeStamp:       ev.TimeStamp,
+		EventTimeStamp:  ev.EventTimeStamp,
+	}
+	if matched, err := sb.dispatchHearActions(mentionAsMessage, messageWriter); matched {
+		if err != nil {
+			sb.l.Errorf("Hear action failed: %v", err)
+		}
+		if writer, ok := messageWriter.(*multiWriter); ok {
+			if err := writer.Flush(); err != nil {
+				sb.l.Errorf("Failed to flush hear-action response: %v", err)
+			}
+		}
+		sb.recordReply(ev.Channel, ev.User, ev.TimeStamp)
+		return
+	}
 
 	// Process the message and collect the response
-	str, err := sb.lambdaChat.Chat(userID, text)
+	var str string
+	err := sb.telemetry.traceChat(ctx, "lambdachat.Chat", func(ctx context.Context) error {
+		var err error
+		str, err = sb.lambdaChat.Chat(userID, text)
+		return err
+	})
 	if err != nil {
 		sb.l.Errorf("Failed to process message: %v", err)
-		_, _, err = sb.client.Client.PostMessage(
-			ev.Channel,
-			slack.MsgOptionText(fmt.Sprintf("Error: %v", err), false),
-			slack.MsgOptionTS(threadTs),
-		)
-		if err != nil {
-			sb.l.Errorf("Failed to post error message: %v", err)
+		postErr := sb.telemetry.traceSlackCall(ctx, "slack.chat.postMessage", func(ctx context.Context) error {
+			_, _, err := sb.client.Client.PostMessageContext(
+				ctx,
+				ev.Channel,
+				slack.MsgOptionText(fmt.Sprintf("Error: %v", err), false),
+				slack.MsgOptionTS(threadTs),
+			)
+			return err
+		})
+		if postErr != nil {
+			sb.l.Errorf("Failed to post error message: %v", postErr)
 		}
 		return
 	}
@@ -342,6 +660,8 @@ func (sb *slackBot) handleAppMention(ev *slackevents.AppMentionEvent) {
 			sb.l.Errorf("Failed to flush app mention response: %v", err)
 		}
 	}
+
+	sb.recordReply(ev.Channel, ev.User, ev.TimeStamp)
 }
 
 // handleDirectMessage handles direct messages to the bot
@@ -349,9 +669,16 @@ func (sb *slackBot) handleDirectMessage(ev *slackevents.MessageEvent) {
 	// Create a unique user ID using the Slack user ID
 	userID := fmt.Sprintf("slack-user-%s", ev.User)
 
+	ctx, span := sb.telemetry.startEventSpan(context.Background(), "slackbot.handleDirectMessage", "direct_message", ev.Channel, ev.User)
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("model", sb.lambdaChat.CurrentModel(userID)),
+		attribute.String("persona", sb.lambdaChat.CurrentPersona(userID)),
+	)
+
 	// Log the user input to the web UI
 	if sb.webUI != nil {
-		sb.webUI.LogUserInput(ev.User, ev.Channel, ev.Text)
+		sb.webUI.LogUserInput(ev.User, slackChannel(ev.Channel), ev.Text)
 	}
 
 	// Determine thread timestamp to use:
@@ -376,6 +703,8 @@ func (sb *slackBot) handleDirectMessage(ev *slackevents.MessageEvent) {
 			// Reset context for expired thread
 			_ = sb.lambdaChat.Reset(userID)
 			sb.l.Infof("Thread expired, reloading context for user %s in channel %s", ev.User, ev.Channel)
+			sb.telemetry.recordThreadExpired(ctx)
+			sb.telemetry.recordThreadReset(ctx)
 
 			// Use the existing thread but update its timestamp
 			threadTs = threadData.threadTs
@@ -388,18 +717,40 @@ func (sb *slackBot) handleDirectMessage(ev *slackevents.MessageEvent) {
 	}
 
 	// Create a message writer that will collect the Slack thread message
-	messageWriter := sb.createThreadMessageWriter(ev.Channel, ev.User, threadTs)
+	messageWriter := sb.createThreadMessageWriter(ctx, ev.Channel, ev.User, threadTs)
+
+	// Give registered hear actions first refusal on the message before it
+	// falls through to the LLM.
+	if matched, err := sb.dispatchHearActions(ev, messageWriter); matched {
+		if err != nil {
+			sb.l.Errorf("Hear action failed: %v", err)
+		}
+		if writer, ok := messageWriter.(*multiWriter); ok {
+			if err := writer.Flush(); err != nil {
+				sb.l.Errorf("Failed to flush hear-action response: %v", err)
+			}
+		}
+		sb.recordReply(ev.Channel, ev.User, ev.TimeStamp)
+		return
+	}
 
 	// Process the message and collect the response
-	err := sb.lambdaChat.ChatStream(userID, ev.Text, messageWriter)
+	err := sb.telemetry.traceChat(ctx, "lambdachat.ChatStream", func(ctx context.Context) error {
+		return sb.lambdaChat.ChatStream(userID, ev.Text, messageWriter)
+	})
 	if err != nil {
 		sb.l.Errorf("Failed to process message: %v", err)
-		if _, _, err = sb.client.Client.PostMessage(
-			ev.Channel,
-			slack.MsgOptionText(fmt.Sprintf("Error: %v", err), false),
-			slack.MsgOptionTS(threadTs),
-		); err != nil {
-			sb.l.Errorf("Failed to post error message: %v", err)
+		postErr := sb.telemetry.traceSlackCall(ctx, "slack.chat.postMessage", func(ctx context.Context) error {
+			_, _, err := sb.client.Client.PostMessageContext(
+				ctx,
+				ev.Channel,
+				slack.MsgOptionText(fmt.Sprintf("Error: %v", err), false),
+				slack.MsgOptionTS(threadTs),
+			)
+			return err
+		})
+		if postErr != nil {
+			sb.l.Errorf("Failed to post error message: %v", postErr)
 		}
 		return
 	}
@@ -410,13 +761,23 @@ func (sb *slackBot) handleDirectMessage(ev *slackevents.MessageEvent) {
 			sb.l.Errorf("Failed to flush direct message response: %v", err)
 		}
 	}
+
+	sb.recordReply(ev.Channel, ev.User, ev.TimeStamp)
 }
 
-// handleSlashCommand handles slash commands
+// handleSlashCommand dispatches a slash command to whichever registered
+// Plugin claimed its name.
 func (sb *slackBot) handleSlashCommand(cmd slack.SlashCommand) {
 	// Create a unique user ID using the Slack user ID
 	userID := fmt.Sprintf("slack-user-%s", cmd.UserID)
 
+	_, span := sb.telemetry.startEventSpan(context.Background(), "slackbot.handleSlashCommand", "slash_command", cmd.ChannelID, cmd.UserID)
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("model", sb.lambdaChat.CurrentModel(userID)),
+		attribute.String("persona", sb.lambdaChat.CurrentPersona(userID)),
+	)
+
 	lm := fmt.Sprintf("User %s event", userID)
 	defer func() {
 		if sb.webUI != nil {
@@ -424,164 +785,210 @@ func (sb *slackBot) handleSlashCommand(cmd slack.SlashCommand) {
 		}
 	}()
 
-	// Handle different commands
-	switch strings.ToLower(cmd.Command) {
-	case "/reset":
-		_ = sb.lambdaChat.Reset(userID)
-		sb.threadsMu.Lock()
-		delete(sb.activeThreads, fmt.Sprintf("%s-%s", cmd.ChannelID, cmd.UserID))
-		sb.threadsMu.Unlock()
-
-		// Send a confirmation message
-		if _, _, msgErr := sb.client.Client.PostMessage(
-			cmd.ChannelID,
-			slack.MsgOptionText("*Conversation has been reset.*", false),
-			slack.MsgOptionPostEphemeral(cmd.UserID),
-		); msgErr != nil {
-			_, _, msgErr = sb.client.Client.PostMessage(cmd.ChannelID, slack.MsgOptionText("*Conversation has been reset.*", false))
-			sb.l.Errorf("Failed to post reset confirmation: %v", msgErr)
-		}
+	name := strings.ToLower(strings.TrimPrefix(cmd.Command, "/"))
+	command, ok := sb.commands[name]
+	if !ok {
+		sb.l.Warnf("No plugin registered for command %s", cmd.Command)
+		return
+	}
 
-		lm = fmt.Sprintf("User %s reset conversation", cmd.UserID)
-
-	case "/persona":
-		if cmd.Text == "" {
-			// No persona name provided, so list available personas
-			personas := sb.lambdaChat.GetAvailablePersonas()
-			message := "*Available personas:*\n• " + strings.Join(personas, "\n• ")
-
-			if _, _, msgErr := sb.client.Client.PostMessage(
-				cmd.ChannelID,
-				slack.MsgOptionText(message, false),
-				slack.MsgOptionPostEphemeral(cmd.UserID),
-			); msgErr != nil {
-				_, _, msgErr = sb.client.Client.PostMessage(cmd.ChannelID, slack.MsgOptionText(message, false))
-				sb.l.Errorf("Failed to post personas list: %v", msgErr)
-			}
+	if err := command.Handler(&Context{bot: sb}, cmd); err != nil {
+		sb.l.Errorf("Failed to handle %s: %v", cmd.Command, err)
+		sb.telemetry.recordError(context.Background(), "command")
+		return
+	}
 
-			lm = fmt.Sprintf("User %s requested available personas", cmd.UserID)
-			return
-		}
+	lm = fmt.Sprintf("User %s ran %s", userID, cmd.Command)
+}
 
-		response, err := sb.lambdaChat.SetPersona(userID, cmd.Text)
-		if err != nil {
-			sb.l.Errorf("Failed to set persona: %v", err)
-			errorMsg := fmt.Sprintf("Error setting persona: %v\nAvailable personas: %s",
-				err, strings.Join(sb.lambdaChat.GetAvailablePersonas(), ", "))
-
-			if _, _, msgErr := sb.client.Client.PostMessage(
-				cmd.ChannelID,
-				slack.MsgOptionText(errorMsg, false),
-				slack.MsgOptionPostEphemeral(cmd.UserID),
-			); msgErr != nil {
-				_, _, msgErr = sb.client.Client.PostMessage(cmd.ChannelID, slack.MsgOptionText(errorMsg, false))
-				sb.l.Errorf("Failed to post error message: %v", msgErr)
-			}
-			return
-		}
+// handleInteraction handles a Block Kit action click: the
+// "set_model:<name>"/"set_persona:<name>" buttons posted by corePlugin's
+// /models and /personas commands, and the "regenerate"/"reset_conversation"/
+// "show_personas"/"show_models" buttons attached to every rendered reply. It
+// applies the action and updates the ephemeral message in place via
+// callback.ResponseURL.
+func (sb *slackBot) handleInteraction(callback slack.InteractionCallback) {
+	if callback.Type != slack.InteractionTypeBlockActions {
+		return
+	}
 
-		var msgErr error
-		if _, _, msgErr = sb.client.Client.PostMessage(
-			cmd.ChannelID,
-			slack.MsgOptionText(response, false),
-			slack.MsgOptionPostEphemeral(cmd.UserID),
-		); msgErr != nil {
-			_, _, msgErr = sb.client.Client.PostMessage(cmd.ChannelID, slack.MsgOptionText(response, false))
-			sb.l.Errorf("Failed to post persona change confirmation: %v", msgErr)
+	for _, action := range callback.ActionCallback.BlockActions {
+		sb.handleBlockAction(callback, action)
+	}
+}
+
+func (sb *slackBot) handleBlockAction(callback slack.InteractionCallback, action *slack.BlockAction) {
+	userID := fmt.Sprintf("slack-user-%s", callback.User.ID)
+
+	var response string
+	var blocks []slack.Block
+	var err error
+	switch {
+	case strings.HasPrefix(action.ActionID, "set_model:"):
+		response, err = sb.lambdaChat.SetModel(userID, strings.TrimPrefix(action.ActionID, "set_model:"))
+	case strings.HasPrefix(action.ActionID, "set_persona:"):
+		response, err = sb.lambdaChat.SetPersona(userID, strings.TrimPrefix(action.ActionID, "set_persona:"))
+	case action.ActionID == "regenerate":
+		response, err = sb.lambdaChat.Regenerate(userID)
+		if err == nil {
+			blocks = replyBlocks(response, sb.lambdaChat.CurrentModel(userID), sb.lambdaChat.CurrentPersona(userID))
 		}
+	case action.ActionID == "reset_conversation":
+		err = sb.lambdaChat.Reset(userID)
+		response = "Conversation has been reset."
+	case action.ActionID == "show_personas":
+		response = "*Available personas:*"
+		blocks = personaBlocks(response, sb.lambdaChat.PersonaInfo())
+	case action.ActionID == "show_models":
+		response = "*Available models:*"
+		blocks = modelBlocks(response, sb.lambdaChat.ModelInfo())
+	default:
+		return
+	}
 
-		lm = fmt.Sprintf("User %s changed persona to %s", cmd.UserID, cmd.Text)
+	if err != nil {
+		response, blocks = fmt.Sprintf("Error: %v", err), nil
+	}
 
-	case "/personas":
-		personas := sb.lambdaChat.GetAvailablePersonas()
-		message := "*Available personas:*\n• " + strings.Join(personas, "\n• ")
+	webhookMsg := &slack.WebhookMessage{Text: response, ReplaceOriginal: true}
+	if blocks != nil {
+		webhookMsg.Blocks = &slack.Blocks{BlockSet: blocks}
+	}
 
-		if _, _, msgErr := sb.client.Client.PostMessage(
-			cmd.ChannelID,
-			slack.MsgOptionText(message, false),
-			slack.MsgOptionPostEphemeral(cmd.UserID),
-		); msgErr != nil {
-			_, _, msgErr = sb.client.Client.PostMessage(cmd.ChannelID, slack.MsgOptionText(message, false))
-			sb.l.Errorf("Failed to post personas list: %v", msgErr)
-		}
-		lm = fmt.Sprintf("User %s requested available personas", cmd.UserID)
-
-	case "/models":
-		// List available models
-		models := sb.lambdaChat.GetAvailableModels()
-		message := "*Available models:*\n• " + strings.Join(models, "\n• ")
-
-		if _, _, msgErr := sb.client.Client.PostMessage(
-			cmd.ChannelID,
-			slack.MsgOptionText(message, false),
-			slack.MsgOptionPostEphemeral(cmd.UserID),
-		); msgErr != nil {
-			_, _, msgErr = sb.client.Client.PostMessage(cmd.ChannelID, slack.MsgOptionText(message, false))
-			sb.l.Errorf("Failed to post models list: %v", msgErr)
-		}
+	if err := slack.PostWebhook(callback.ResponseURL, webhookMsg); err != nil {
+		sb.l.Errorf("Failed to update message for interaction %s: %v", action.ActionID, err)
+	}
+}
 
-	case "/model":
-		if cmd.Text == "" {
-			models := sb.lambdaChat.GetAvailableModels()
-			message := "*Available models:*\n• " + strings.Join(models, "\n• ")
-
-			if _, _, msgErr := sb.client.Client.PostMessage(
-				cmd.ChannelID,
-				slack.MsgOptionText(message, false),
-				slack.MsgOptionPostEphemeral(cmd.UserID),
-			); msgErr != nil {
-				_, _, msgErr = sb.client.Client.PostMessage(cmd.ChannelID, slack.MsgOptionText(message, false))
-				sb.l.Errorf("Failed to post models list: %v", msgErr)
-			}
+// recordReply remembers the bot's reply to the triggering message at
+// channel+triggerTs, so a later edit or delete of that message can find and
+// update or delete the reply. It's a no-op if the bot never actually posted
+// (e.g. the chat call failed before anything was written).
+func (sb *slackBot) recordReply(channel, user, triggerTs string) {
+	state, ok := sb.streamCache.get(streamKey{channel: channel, user: user})
+	if !ok {
+		return
+	}
 
-			lm = fmt.Sprintf("User %s requested available models", cmd.UserID)
-			return
-		}
+	state.mu.Lock()
+	responseTs := state.responseTs
+	threadTs := state.parentTs
+	state.mu.Unlock()
+
+	if responseTs == "" {
+		return
+	}
 
-		stripped := strings.Replace(cmd.Text, "*", "", -1)
-		response, err := sb.lambdaChat.SetModel(userID, stripped)
+	sb.replyCache.set(messageRef{channel: channel, ts: triggerTs}, botResponse{
+		channel:  channel,
+		ts:       responseTs,
+		threadTs: threadTs,
+	})
+}
 
-		if err != nil {
-			sb.l.Errorf("Failed to set model: %v", err)
-			errorMsg := fmt.Sprintf("Error setting model: %v\nAvailable Models: \n• %s",
-				err, strings.Join(sb.lambdaChat.GetAvailableModels(), "\n• "))
-
-			if _, _, msgErr := sb.client.Client.PostMessage(
-				cmd.ChannelID,
-				slack.MsgOptionText(errorMsg, false),
-				slack.MsgOptionPostEphemeral(cmd.UserID),
-			); msgErr != nil {
-				_, _, msgErr = sb.client.Client.PostMessage(cmd.ChannelID, slack.MsgOptionText(errorMsg, false))
-				sb.l.Errorf("Failed to post models list: %v", msgErr)
-			}
-			return
-		}
+// handleMessageChanged re-answers an edited message in place: it resets the
+// user's conversation (since the original reply was answering an earlier
+// version of the message), re-runs the chat against the new text, and
+// updates the existing bot reply instead of posting a new one.
+func (sb *slackBot) handleMessageChanged(ev *slackevents.MessageEvent) {
+	if !sb.reactToEdits || ev.Message == nil || ev.Message.User == "" {
+		return
+	}
 
-		if _, _, msgErr := sb.client.Client.PostMessage(cmd.ChannelID, slack.MsgOptionText(response, false)); msgErr != nil {
-			sb.l.Errorf("Failed to post model change confirmation: %v", msgErr)
+	reply, ok := sb.replyCache.get(messageRef{channel: ev.Channel, ts: ev.Message.TimeStamp})
+	if !ok {
+		return
+	}
+
+	userID := fmt.Sprintf("slack-user-%s", ev.Message.User)
+
+	if sb.webUI != nil {
+		sb.webUI.LogUserInput(ev.Message.User, slackChannel(ev.Channel), ev.Message.Text)
+	}
+
+	if err := sb.lambdaChat.Reset(userID); err != nil {
+		sb.l.Errorf("Failed to reset conversation for edited message: %v", err)
+		return
+	}
+	sb.telemetry.recordThreadReset(context.Background())
+
+	messageWriter := sb.createReplyMessageWriter(context.Background(), ev.Channel, ev.Message.User, reply)
+
+	if err := sb.lambdaChat.ChatStream(userID, ev.Message.Text, messageWriter); err != nil {
+		sb.l.Errorf("Failed to process edited message: %v", err)
+		return
+	}
+
+	if writer, ok := messageWriter.(*multiWriter); ok {
+		if err := writer.Flush(); err != nil {
+			sb.l.Errorf("Failed to flush edited message response: %v", err)
 		}
+	}
+
+	sb.recordReply(ev.Channel, ev.Message.User, ev.Message.TimeStamp)
+}
 
-		lm = fmt.Sprintf("User %s changed model to %s", cmd.UserID, cmd.Text)
+// handleMessageDeleted deletes the bot's reply to a deleted message, if any.
+func (sb *slackBot) handleMessageDeleted(ev *slackevents.MessageEvent) {
+	ref := messageRef{channel: ev.Channel, ts: ev.DeletedTimeStamp}
+	reply, ok := sb.replyCache.get(ref)
+	if !ok {
+		return
 	}
+
+	err := sb.telemetry.traceSlackCall(context.Background(), "slack.chat.delete", func(ctx context.Context) error {
+		_, _, err := sb.client.Client.DeleteMessageContext(ctx, reply.channel, reply.ts)
+		return err
+	})
+	if err != nil {
+		sb.l.Errorf("Failed to delete bot reply to deleted message: %v", err)
+		return
+	}
+
+	sb.replyCache.delete(ref)
 }
 
-// createThreadMessageWriter creates a writer that will update a Slack message in a thread
-func (sb *slackBot) createThreadMessageWriter(channel, user, threadTs string) io.Writer {
-	// Create a unique key for this message
-	key := fmt.Sprintf("%s-%s", channel, user)
+// createReplyMessageWriter creates a writer that updates an existing Slack
+// message (the bot's prior reply) instead of posting a new one, for
+// re-answering an edited message. ctx is used to parent the spans around
+// the Slack API calls it makes.
+func (sb *slackBot) createReplyMessageWriter(ctx context.Context, channel, user string, reply botResponse) io.Writer {
+	key := streamKey{channel: channel, user: user}
+	state := &streamState{parentTs: reply.threadTs, responseTs: reply.ts}
+	sb.streamCache.set(key, state)
 
-	// Create a new buffer for this message
-	sb.messageMu.Lock()
-	sb.messageBuffers[key] = &strings.Builder{}
-	sb.messageMu.Unlock()
+	return &multiWriter{
+		slackWriter: &threadMessageWriter{
+			bot:     sb,
+			ctx:     ctx,
+			channel: channel,
+			user:    user,
+			state:   state,
+		},
+		webUI:   sb.webUI,
+		user:    user,
+		channel: channel,
+		content: new(strings.Builder),
+	}
+}
+
+// createThreadMessageWriter creates a writer that streams a response into a
+// single Slack message: the first Write posts a placeholder message in the
+// thread, and later Writes coalesce into periodic chat.update calls against
+// it, so long responses appear token-by-token instead of all at once. ctx
+// is used to parent the spans around the Slack API calls it makes.
+func (sb *slackBot) createThreadMessageWriter(ctx context.Context, channel, user, threadTs string) io.Writer {
+	key := streamKey{channel: channel, user: user}
+	state := &streamState{parentTs: threadTs}
+	sb.streamCache.set(key, state)
 
-	// Return a writer that will update the Slack message as the response is generated
 	return &multiWriter{
 		slackWriter: &threadMessageWriter{
-			bot:      sb,
-			channel:  channel,
-			key:      key,
-			threadTs: threadTs,
+			bot:     sb,
+			ctx:     ctx,
+			channel: channel,
+			user:    user,
+			state:   state,
 		},
 		webUI:   sb.webUI,
 		user:    user,
@@ -590,50 +997,119 @@ func (sb *slackBot) createThreadMessageWriter(channel, user, threadTs string) io
 	}
 }
 
-// threadMessageWriter is a writer that collects the entire response and then posts it in a thread
+// threadMessageWriter incrementally streams a response into a single Slack
+// message, rate-limiting how often it calls chat.update.
 type threadMessageWriter struct {
-	bot      *slackBot
-	channel  string
-	key      string
-	threadTs string
+	bot     *slackBot
+	ctx     context.Context
+	channel string
+	user    string
+	state   *streamState
+
+	mu      sync.Mutex
+	content strings.Builder
 }
 
 // Write implements the io.Writer interface
 func (w *threadMessageWriter) Write(p []byte) (n int, err error) {
-	// Add the new content to the buffer
-	w.bot.messageMu.Lock()
-	buffer, ok := w.bot.messageBuffers[w.key]
-	if !ok {
-		w.bot.messageMu.Unlock()
-		return 0, fmt.Errorf("buffer not found for key %s", w.key)
+	w.mu.Lock()
+	n, err = w.content.Write(p)
+	content := w.content.String()
+	w.mu.Unlock()
+	if err != nil {
+		return n, err
 	}
 
-	n, err = buffer.Write(p)
-	w.bot.messageMu.Unlock()
-	return n, err
+	w.state.mu.Lock()
+	due := w.state.responseTs == "" || time.Since(w.state.lastUpdate) >= streamUpdateInterval
+	w.state.mu.Unlock()
+
+	if due {
+		if err := w.send(content, false); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
 }
 
-// Flush writes the collected content to Slack in a thread
+// Flush issues a final chat.update (or, if nothing was posted yet, a single
+// post) with the complete content collected so far, rendered as Block Kit
+// with a model/persona badge, fenced code as rich text, and action buttons.
 func (w *threadMessageWriter) Flush() error {
-	w.bot.messageMu.Lock()
-	buffer, ok := w.bot.messageBuffers[w.key]
-	if !ok {
-		w.bot.messageMu.Unlock()
-		return fmt.Errorf("buffer not found for key %s", w.key)
+	w.mu.Lock()
+	content := w.content.String()
+	w.mu.Unlock()
+	return w.send(content, true)
+}
+
+// send posts the placeholder message on first use, or otherwise updates it
+// in place. If the update fails, e.g. because the message is too old to
+// edit, it falls back to posting a fresh message in the thread. final marks
+// the last call for a given reply, at which point the content is rendered
+// as Block Kit instead of plain text; earlier, partial calls stay plain
+// text since code fences and paragraphs may not have closed yet.
+func (w *threadMessageWriter) send(content string, final bool) error {
+	if content == "" {
+		return nil
 	}
-	content := buffer.String()
-	w.bot.messageMu.Unlock()
 
-	// Send the complete message to Slack in the thread
-	_, _, _, err := w.bot.client.Client.SendMessage(
-		w.channel,
-		slack.MsgOptionText(content, false),
-		slack.MsgOptionTS(w.threadTs), // Reply in the thread
-	)
+	w.state.mu.Lock()
+	responseTs := w.state.responseTs
+	parentTs := w.state.parentTs
+	w.state.mu.Unlock()
+
+	postOptions := []slack.MsgOption{slack.MsgOptionText(content, false)}
+	if final {
+		userID := fmt.Sprintf("slack-user-%s", w.user)
+		blocks := replyBlocks(content, w.bot.lambdaChat.CurrentModel(userID), w.bot.lambdaChat.CurrentPersona(userID))
+		postOptions = append(postOptions, slack.MsgOptionBlocks(blocks...))
+	}
+	if parentTs != "" {
+		postOptions = append(postOptions, slack.MsgOptionTS(parentTs))
+	}
+
+	if responseTs == "" {
+		var ts string
+		err := w.bot.telemetry.traceSlackCall(w.ctx, "slack.chat.postMessage", func(ctx context.Context) error {
+			var err error
+			_, ts, err = w.bot.client.Client.PostMessageContext(ctx, w.channel, postOptions...)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to post thread message: %w", err)
+		}
+
+		w.state.mu.Lock()
+		w.state.responseTs = ts
+		w.state.lastUpdate = time.Now()
+		w.state.mu.Unlock()
+		return nil
+	}
+
+	err := w.bot.telemetry.traceSlackCall(w.ctx, "slack.chat.update", func(ctx context.Context) error {
+		_, _, _, err := w.bot.client.Client.UpdateMessageContext(ctx, w.channel, responseTs, postOptions...)
+		return err
+	})
 	if err != nil {
-		w.bot.l.Errorf("Failed to send complete thread message: %v", err)
+		w.bot.l.Warnf("Failed to update streamed message, falling back to a new post: %v", err)
+
+		var ts string
+		postErr := w.bot.telemetry.traceSlackCall(w.ctx, "slack.chat.postMessage", func(ctx context.Context) error {
+			var err error
+			_, ts, err = w.bot.client.Client.PostMessageContext(ctx, w.channel, postOptions...)
+			return err
+		})
+		if postErr != nil {
+			return fmt.Errorf("failed to post fallback thread message: %w", postErr)
+		}
+		responseTs = ts
 	}
-	return err
+
+	w.state.mu.Lock()
+	w.state.responseTs = responseTs
+	w.state.lastUpdate = time.Now()
+	w.state.mu.Unlock()
+	return nil
 }
 
 // multiWriter is a writer that collects content for both Slack and the web UI
@@ -671,7 +1147,7 @@ func (w *multiWriter) Flush() error {
 
 	// Log the complete response to the web UI
 	if w.webUI != nil && w.content.Len() > 0 {
-		w.webUI.LogResponse(w.user, w.channel, w.content.String())
+		w.webUI.LogResponse(w.user, slackChannel(w.channel), w.content.String())
 	}
 
 	return err
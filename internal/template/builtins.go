@@ -0,0 +1,42 @@
+package template
+
+import "fmt"
+
+// Built-in templates for common open-weight model families, mirroring the
+// chat templates their tokenizer_config.json files ship.
+var (
+	// ChatML is used by Qwen, Hermes, and many other ChatML-trained models.
+	ChatML = mustCompile(
+		`{% for message in messages %}{{ '<|im_start|>' + message['role'] + '\n' + message['content'] + '<|im_end|>' + '\n' }}{% endfor %}{% if add_generation_prompt %}{{ '<|im_start|>assistant\n' }}{% endif %}`,
+		false,
+	)
+
+	// MistralInstruct is Mistral's [INST]/[/INST] instruction format.
+	MistralInstruct = mustCompile(
+		`{% for message in messages %}{% if message['role'] == 'system' %}{{ '[INST] ' + message['content'] + ' [/INST]' }}{% elif message['role'] == 'user' %}{{ '[INST] ' + message['content'] + ' [/INST]' }}{% elif message['role'] == 'assistant' %}{{ ' ' + message['content'] + '</s>' }}{% endif %}{% endfor %}`,
+		false,
+	)
+
+	// Llama3 is Meta's Llama-3 Instruct header format.
+	Llama3 = mustCompile(
+		`{% for message in messages %}{{ '<|start_header_id|>' + message['role'] + '<|end_header_id|>\n\n' + message['content'] + '<|eot_id|>' }}{% endfor %}{% if add_generation_prompt %}{{ '<|start_header_id|>assistant<|end_header_id|>\n\n' }}{% endif %}`,
+		false,
+	)
+
+	// DeepSeekR1 is DeepSeek-R1's format. Its replies wrap their reasoning in
+	// "<think>...</think>" ahead of the visible answer.
+	DeepSeekR1 = mustCompile(
+		`{% for message in messages %}{% if message['role'] == 'system' %}{{ message['content'] }}{% elif message['role'] == 'user' %}{{ '<｜User｜>' + message['content'] }}{% elif message['role'] == 'assistant' %}{{ '<｜Assistant｜>' + message['content'] + '<｜end▁of▁sentence｜>' }}{% endif %}{% endfor %}{% if add_generation_prompt %}{{ '<｜Assistant｜>' }}{% endif %}`,
+		true,
+	)
+)
+
+// mustCompile builds a built-in template, panicking if it fails to compile
+// since a broken built-in is a programming error, not a runtime condition.
+func mustCompile(source string, reasoning bool) ChatTemplate {
+	tpl, err := Compile(source, reasoning)
+	if err != nil {
+		panic(fmt.Sprintf("template: invalid built-in template: %v", err))
+	}
+	return tpl
+}
@@ -0,0 +1,41 @@
+package template
+
+import "strings"
+
+// ForModel guesses a built-in ChatTemplate from a model ID's substrings,
+// matching the family conventions HuggingFace tokenizer configs use. It
+// always returns a usable template, defaulting to ChatML for unrecognized
+// IDs since it's the most common format among open-weight chat models.
+func ForModel(modelID string) ChatTemplate {
+	lower := strings.ToLower(modelID)
+	switch {
+	case strings.Contains(lower, "deepseek"):
+		return DeepSeekR1
+	case strings.Contains(lower, "llama"):
+		return Llama3
+	case strings.Contains(lower, "mistral"):
+		return MistralInstruct
+	case strings.Contains(lower, "qwen"), strings.Contains(lower, "hermes"):
+		return ChatML
+	default:
+		return ChatML
+	}
+}
+
+// Lookup returns the built-in ChatTemplate named by name (e.g. "chatml",
+// "llama3", "mistral", "deepseek-r1"), for a per-model config that wants to
+// name one explicitly rather than relying on ForModel's ID-based guess.
+func Lookup(name string) (ChatTemplate, bool) {
+	switch strings.ToLower(name) {
+	case "chatml":
+		return ChatML, true
+	case "mistral", "mistral-instruct":
+		return MistralInstruct, true
+	case "llama3", "llama-3":
+		return Llama3, true
+	case "deepseek-r1", "deepseek":
+		return DeepSeekR1, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,72 @@
+package template
+
+import "strings"
+
+// maxMarkerLen bounds how many trailing bytes of a streamed buffer might
+// still be an in-progress reasoning marker ("<think>" is the longest one
+// that matters for visible-text safety) and so must be withheld until more
+// data arrives, or Flush is called, to disambiguate them.
+const maxMarkerLen = len("<think>")
+
+// StreamSplitter incrementally separates a model's streamed output into
+// visible and hidden text using a ChatTemplate's ParseThinking. It holds
+// back a marker-length tail of unconfirmed text so that a reasoning marker
+// split across chunk boundaries (e.g. "<thi" then "nk>") never leaks
+// through as visible output.
+type StreamSplitter struct {
+	tpl      ChatTemplate
+	raw      strings.Builder
+	revealed int // bytes of visible text already returned by Write/Flush
+}
+
+// NewStreamSplitter creates a StreamSplitter that uses tpl to decide what's
+// visible versus hidden.
+func NewStreamSplitter(tpl ChatTemplate) *StreamSplitter {
+	return &StreamSplitter{tpl: tpl}
+}
+
+// Write feeds chunk into the splitter and returns any newly-revealed
+// visible text (which may be empty, e.g. while inside a hidden block).
+func (s *StreamSplitter) Write(chunk string) string {
+	s.raw.WriteString(chunk)
+	return s.reveal(safeBoundary(s.raw.String()))
+}
+
+// Flush reveals any text still withheld pending disambiguation, for use
+// once the caller knows no more data is coming.
+func (s *StreamSplitter) Flush() string {
+	return s.reveal(s.raw.String())
+}
+
+// Visible returns everything revealed so far, including text still withheld
+// by Write pending disambiguation (call Flush first to also forward it).
+func (s *StreamSplitter) Visible() string {
+	visible, _ := s.tpl.ParseThinking(s.raw.String())
+	return visible
+}
+
+func (s *StreamSplitter) reveal(safeText string) string {
+	visible, _ := s.tpl.ParseThinking(safeText)
+	if len(visible) <= s.revealed {
+		return ""
+	}
+	newText := visible[s.revealed:]
+	s.revealed = len(visible)
+	return newText
+}
+
+// safeBoundary returns the longest prefix of text that's safe to parse: one
+// that doesn't end mid-marker, so a split write like "<thi" + "nk>" can't
+// leak a partial "<think>" through as visible text.
+func safeBoundary(text string) string {
+	max := maxMarkerLen - 1
+	if max > len(text) {
+		max = len(text)
+	}
+	for tailLen := 1; tailLen <= max; tailLen++ {
+		if strings.HasPrefix("<think>", text[len(text)-tailLen:]) {
+			return text[:len(text)-tailLen]
+		}
+	}
+	return text
+}
@@ -0,0 +1,107 @@
+// Package template renders conversations into the prompt format a given
+// model expects (ChatML, Mistral-Instruct, Llama-3, DeepSeek-R1, or a
+// user-supplied Jinja chat template) and splits a model's raw output into
+// the part meant for the user and any reasoning it should keep hidden.
+package template
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lambda/lambdachat-slackbot/internal/provider"
+	"github.com/nikolalohinski/gonja/v2"
+	"github.com/nikolalohinski/gonja/v2/exec"
+)
+
+// ChatTemplate renders a conversation into a model's native prompt string
+// and splits its raw output into visible text and hidden reasoning.
+type ChatTemplate interface {
+	// Render turns messages into the model's native prompt string.
+	// addGenerationPrompt appends the marker that invites the assistant to
+	// reply, e.g. Llama-3's "<|start_header_id|>assistant<|end_header_id|>".
+	Render(messages []provider.Message, addGenerationPrompt bool) (string, error)
+
+	// ParseThinking splits the model's output so far into the text that
+	// should be shown to the user and any reasoning that should stay
+	// hidden. Pass the full output accumulated so far, not just the newest
+	// delta: ParseThinking re-derives the split from scratch every call,
+	// which is what keeps a marker like "<think>" from leaking into the
+	// visible half when a stream splits it across chunk boundaries.
+	ParseThinking(output string) (visible, hidden string)
+}
+
+// jinjaTemplate implements ChatTemplate by rendering a Jinja chat template
+// string, the same format model authors ship in tokenizer_config.json.
+type jinjaTemplate struct {
+	tpl       *exec.Template
+	reasoning bool // true if replies may contain "<think>...</think>" blocks
+}
+
+// Compile builds a ChatTemplate from a Jinja chat template string, so a
+// per-model config can supply one that doesn't match any of the built-ins.
+// reasoning marks whether the model's replies may contain
+// "<think>...</think>" blocks that ParseThinking should hide.
+func Compile(source string, reasoning bool) (ChatTemplate, error) {
+	tpl, err := gonja.FromString(source)
+	if err != nil {
+		return nil, fmt.Errorf("template: compiling: %w", err)
+	}
+	return &jinjaTemplate{tpl: tpl, reasoning: reasoning}, nil
+}
+
+func (j *jinjaTemplate) Render(messages []provider.Message, addGenerationPrompt bool) (string, error) {
+	rendered, err := j.tpl.ExecuteToString(exec.NewContext(map[string]interface{}{
+		"messages":              jinjaMessages(messages),
+		"add_generation_prompt": addGenerationPrompt,
+	}))
+	if err != nil {
+		return "", fmt.Errorf("template: rendering: %w", err)
+	}
+	return rendered, nil
+}
+
+func (j *jinjaTemplate) ParseThinking(output string) (visible, hidden string) {
+	if !j.reasoning {
+		return output, ""
+	}
+	return splitThinking(output)
+}
+
+// jinjaMessages converts provider.Message into the map shape Jinja chat
+// templates expect: a "role"/"content" pair per turn.
+func jinjaMessages(messages []provider.Message) []map[string]interface{} {
+	out := make([]map[string]interface{}, 0, len(messages))
+	for _, m := range messages {
+		out = append(out, map[string]interface{}{
+			"role":    m.Role,
+			"content": m.Content,
+		})
+	}
+	return out
+}
+
+// splitThinking splits s on "<think>"/"</think>" pairs, treating everything
+// from an unterminated "<think>" to the end of s as still-in-progress
+// hidden reasoning. Text before "<think>" and after "</think>" is visible.
+func splitThinking(s string) (visible, hidden string) {
+	var vis, hid strings.Builder
+	rest := s
+	for {
+		start := strings.Index(rest, "<think>")
+		if start == -1 {
+			vis.WriteString(rest)
+			break
+		}
+		vis.WriteString(rest[:start])
+		rest = rest[start+len("<think>"):]
+
+		end := strings.Index(rest, "</think>")
+		if end == -1 {
+			hid.WriteString(rest)
+			break
+		}
+		hid.WriteString(rest[:end])
+		rest = rest[end+len("</think>"):]
+	}
+	return vis.String(), hid.String()
+}
@@ -0,0 +1,104 @@
+package template
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lambda/lambdachat-slackbot/internal/provider"
+)
+
+func TestForModel(t *testing.T) {
+	cases := map[string]ChatTemplate{
+		"deepseek-llama3.3-70b": DeepSeekR1,
+		"Meta-Llama-3.1-8B":     Llama3,
+		"mistral-7b-instruct":   MistralInstruct,
+		"qwen-25-coder":         ChatML,
+		"some-unknown-model":    ChatML,
+	}
+	for modelID, want := range cases {
+		if got := ForModel(modelID); got != want {
+			t.Errorf("ForModel(%q) = %p, want %p", modelID, got, want)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	if tpl, ok := Lookup("llama-3"); !ok || tpl != Llama3 {
+		t.Errorf("Lookup(%q) = %v, %v; want Llama3, true", "llama-3", tpl, ok)
+	}
+	if _, ok := Lookup("not-a-template"); ok {
+		t.Errorf("Lookup of an unknown name should report false")
+	}
+}
+
+func TestChatMLRender(t *testing.T) {
+	rendered, err := ChatML.Render([]provider.Message{
+		{Role: "user", Content: "hi"},
+	}, true)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	want := "<|im_start|>user\nhi<|im_end|>\n<|im_start|>assistant\n"
+	if rendered != want {
+		t.Errorf("Render() = %q, want %q", rendered, want)
+	}
+}
+
+func TestParseThinkingNonReasoningModel(t *testing.T) {
+	visible, hidden := ChatML.ParseThinking("<think>this looks like reasoning but isn't</think>visible")
+	if hidden != "" || visible != "<think>this looks like reasoning but isn't</think>visible" {
+		t.Errorf("A non-reasoning template must not split on <think>, got visible=%q hidden=%q", visible, hidden)
+	}
+}
+
+func TestParseThinkingReasoningModel(t *testing.T) {
+	visible, hidden := DeepSeekR1.ParseThinking("<think>reasoning here</think>the answer")
+	if visible != "the answer" || hidden != "reasoning here" {
+		t.Errorf("ParseThinking() = visible=%q hidden=%q, want visible=%q hidden=%q", visible, hidden, "the answer", "reasoning here")
+	}
+}
+
+// TestStreamSplitterHidesSplitMarker feeds the opening "<think>" marker
+// across two separate Write calls, verifying the splitter never leaks a
+// partial marker through as visible text.
+func TestStreamSplitterHidesSplitMarker(t *testing.T) {
+	s := NewStreamSplitter(DeepSeekR1)
+
+	var revealed strings.Builder
+	revealed.WriteString(s.Write("<thi"))
+	revealed.WriteString(s.Write("nk>reasoning</think>"))
+	revealed.WriteString(s.Write("hello"))
+
+	if revealed.String() != "hello" {
+		t.Errorf("Expected only the post-</think> text to be revealed incrementally, got %q", revealed.String())
+	}
+	if got := s.Visible(); got != "hello" {
+		t.Errorf("Visible() = %q, want %q", got, "hello")
+	}
+}
+
+func TestStreamSplitterFlushRevealsWithheldTail(t *testing.T) {
+	s := NewStreamSplitter(DeepSeekR1)
+
+	// "<thi" could still be the start of "<think>", so it must be withheld
+	// until either more input disambiguates it or Flush is called.
+	if got := s.Write("plain<thi"); got != "plain" {
+		t.Errorf("Write() = %q, want %q (the ambiguous tail withheld)", got, "plain")
+	}
+	if got := s.Flush(); got != "<thi" {
+		t.Errorf("Flush() = %q, want the withheld tail %q once disambiguation ends", got, "<thi")
+	}
+}
+
+func TestStreamSplitterNoDuplicateReveal(t *testing.T) {
+	s := NewStreamSplitter(ChatML)
+
+	first := s.Write("hello ")
+	second := s.Write("world")
+	if first != "hello " || second != "world" {
+		t.Errorf("Expected each Write to reveal only its new text, got %q then %q", first, second)
+	}
+	if got := s.Visible(); got != "hello world" {
+		t.Errorf("Visible() = %q, want %q", got, "hello world")
+	}
+}
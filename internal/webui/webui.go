@@ -1,10 +1,12 @@
 package webui
 
 import (
+	"crypto/subtle"
 	"encoding/json"
 	"fmt"
-	"log"
+	"io"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,58 +16,241 @@ import (
 
 // Entry represents a log entry to be displayed in the UI
 type Entry struct {
-	Type      string    `json:"type"`      // "log", "user-input", "response"
-	Content   string    `json:"content"`   // The actual log message/user input/response
+	Type      string    `json:"type"`      // "log", "user-input", "response", "response-chunk", "tool-call"
+	Content   string    `json:"content"`   // The actual log message/user input/response/tool result
 	Timestamp time.Time `json:"timestamp"` // Time of the event
-	Channel   string    `json:"channel"`   // Slack channel where the event occurred
-	User      string    `json:"user"`      // Slack user ID
+	Channel   string    `json:"channel"`   // Channel/transport where the event occurred
+	User      string    `json:"user"`      // User ID
+}
+
+// clientRingSize bounds how many not-yet-delivered entries are buffered per
+// WebSocket client before the oldest is dropped, so one slow browser tab
+// can't cause addEntry to drop broadcasts meant for every other client.
+const clientRingSize = 256
+
+// ChatBackend lets the WebUI drive chat turns and admin actions against a
+// lambdaChat instance without depending on the lambdachat package, the same
+// reasoning behind the healthProvider closure below.
+type ChatBackend struct {
+	// Chat runs one non-streaming chat turn for userID.
+	Chat func(userID, message string) (string, error)
+
+	// ChatStream runs one chat turn for userID, writing response tokens to w.
+	ChatStream func(userID, message string, w io.Writer) error
+
+	// ListConversations returns a JSON-marshalable summary of userID's
+	// conversations for GET /api/conversations/{user}.
+	ListConversations func(userID string) (any, error)
+
+	// Reset starts a fresh conversation for userID, for POST /api/reset/{user}.
+	Reset func(userID string) error
+
+	// ModelInfo returns a JSON-marshalable summary of available models for
+	// GET /api/models.
+	ModelInfo func() any
+
+	// SetModel changes userID's model, for POST /api/model/{user}.
+	SetModel func(userID, modelName string) (string, error)
 }
 
 // WebUI is responsible for serving the web UI and streaming logs
 type WebUI struct {
-	l           *logrus.Entry
-	upgrader    websocket.Upgrader
-	clients     map[*websocket.Conn]bool
-	clientsMu   sync.Mutex
-	broadcastCh chan Entry
-	entries     []Entry // Store recent entries to send to new clients
-	entriesMu   sync.Mutex
-	maxEntries  int
-}
-
-// New creates a new WebUI instance
-func New(l *logrus.Entry) *WebUI {
+	l          *logrus.Entry
+	token      string
+	upgrader   websocket.Upgrader
+	clients    map[*websocket.Conn]*wsClient
+	clientsMu  sync.Mutex
+	entries    []Entry // Store recent entries to send to new clients
+	entriesMu  sync.Mutex
+	maxEntries int
+
+	// healthProvider, if set, answers /api/health with its result marshaled
+	// as JSON. Set via SetHealthProvider so webui doesn't need to depend on
+	// the lambdachat package for one JSON-shaped type, the same reasoning
+	// behind the toolObserver closure lambdachat calls into this package.
+	healthProvider func() any
+
+	// chatBackend, if set, answers the chat/admin endpoints; see ChatBackend.
+	chatBackend ChatBackend
+}
+
+// New creates a new WebUI instance. token, if non-empty, is required as a
+// bearer token (HTTP endpoints) or "token" query parameter (the WebSocket
+// handshake, since browser WebSocket clients can't set custom headers) on
+// every endpoint that can read or change chat state. An empty token skips
+// authentication, for local development only; the WebSocket handshake then
+// accepts only same-origin (or Origin-less) requests.
+func New(l *logrus.Entry, token string) *WebUI {
 	return &WebUI{
-		l: l,
+		l:     l,
+		token: token,
 		upgrader: websocket.Upgrader{
 			ReadBufferSize:  1024,
 			WriteBufferSize: 1024,
 			CheckOrigin: func(r *http.Request) bool {
-				// Allow all connections for simplicity
-				return true
+				// A configured token already authenticates the handshake
+				// (see requireToken), so any origin may attempt it. Without
+				// one, fall back to a same-origin check instead of the
+				// previous "allow everything", since the socket now accepts
+				// writes (inbound chat messages).
+				if token != "" {
+					return true
+				}
+				origin := r.Header.Get("Origin")
+				return origin == "" || origin == "http://"+r.Host || origin == "https://"+r.Host
 			},
 		},
-		clients:     make(map[*websocket.Conn]bool),
-		broadcastCh: make(chan Entry, 100),
-		entries:     make([]Entry, 0, 100),
-		maxEntries:  100, // Store last 100 entries
+		clients:    make(map[*websocket.Conn]*wsClient),
+		entries:    make([]Entry, 0, 100),
+		maxEntries: 100, // Store last 100 entries
 	}
 }
 
 // Start starts the web UI server
 func (w *WebUI) Start(addr string) error {
+	mux := http.NewServeMux()
+
 	// Serve static files
-	http.Handle("/", http.FileServer(http.Dir("internal/webui/static")))
+	mux.Handle("/", http.FileServer(http.Dir("internal/webui/static")))
+
+	// WebSocket endpoint: bidirectional log stream plus a browser-driven chat console.
+	mux.HandleFunc("/ws", w.requireToken(w.handleWebSocket))
 
-	// WebSocket endpoint
-	http.HandleFunc("/ws", w.handleWebSocket)
+	// Model health endpoint
+	mux.HandleFunc("/api/health", w.requireToken(w.handleHealth))
 
-	// Start the broadcast goroutine
-	go w.broadcastMessages()
+	// Admin panel endpoints, backed by ChatBackend.
+	mux.HandleFunc("GET /api/conversations/{user}", w.requireToken(w.handleConversations))
+	mux.HandleFunc("POST /api/reset/{user}", w.requireToken(w.handleReset))
+	mux.HandleFunc("GET /api/models", w.requireToken(w.handleModels))
+	mux.HandleFunc("POST /api/model/{user}", w.requireToken(w.handleSetModel))
 
 	// Start the HTTP server
 	w.l.Infof("WebUI server started on %s", addr)
-	return http.ListenAndServe(addr, nil)
+	return http.ListenAndServe(addr, mux)
+}
+
+// SetHealthProvider registers the function /api/health serves its JSON
+// response from. Call before Start; nil disables the endpoint (it responds
+// 404).
+func (w *WebUI) SetHealthProvider(f func() any) {
+	w.healthProvider = f
+}
+
+// SetChatBackend registers the functions the chat console and admin panel
+// endpoints are served from. Call before Start; a zero-value field disables
+// its endpoint (it responds 404).
+func (w *WebUI) SetChatBackend(cb ChatBackend) {
+	w.chatBackend = cb
+}
+
+// requireToken wraps next so it 401s unless the request carries w.token,
+// either as "Authorization: Bearer <token>" or a "token" query parameter.
+// If no token is configured, next runs unconditionally (local development).
+func (w *WebUI) requireToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		if w.token == "" {
+			next(rw, req)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(requestToken(req)), []byte(w.token)) == 1 {
+			next(rw, req)
+			return
+		}
+		http.Error(rw, "unauthorized", http.StatusUnauthorized)
+	}
+}
+
+// requestToken extracts a bearer token from req, preferring the
+// Authorization header and falling back to a "token" query parameter for
+// clients (like a browser's WebSocket constructor) that can't set headers.
+func requestToken(req *http.Request) string {
+	if auth := req.Header.Get("Authorization"); auth != "" {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+	return req.URL.Query().Get("token")
+}
+
+// handleHealth serves the current model health snapshot from
+// healthProvider, or 404 if none was registered.
+func (w *WebUI) handleHealth(rw http.ResponseWriter, req *http.Request) {
+	if w.healthProvider == nil {
+		http.NotFound(rw, req)
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(w.healthProvider()); err != nil {
+		w.l.WithError(err).Error("Failed to encode health response")
+	}
+}
+
+// handleConversations serves userID's conversations as JSON.
+func (w *WebUI) handleConversations(rw http.ResponseWriter, req *http.Request) {
+	if w.chatBackend.ListConversations == nil {
+		http.NotFound(rw, req)
+		return
+	}
+	convos, err := w.chatBackend.ListConversations(req.PathValue("user"))
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(convos); err != nil {
+		w.l.WithError(err).Error("Failed to encode conversations response")
+	}
+}
+
+// handleReset resets userID's conversation.
+func (w *WebUI) handleReset(rw http.ResponseWriter, req *http.Request) {
+	if w.chatBackend.Reset == nil {
+		http.NotFound(rw, req)
+		return
+	}
+	if err := w.chatBackend.Reset(req.PathValue("user")); err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// handleModels serves the available models as JSON.
+func (w *WebUI) handleModels(rw http.ResponseWriter, req *http.Request) {
+	if w.chatBackend.ModelInfo == nil {
+		http.NotFound(rw, req)
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(w.chatBackend.ModelInfo()); err != nil {
+		w.l.WithError(err).Error("Failed to encode models response")
+	}
+}
+
+// handleSetModel changes userID's model from a JSON body of {"model": "..."}.
+func (w *WebUI) handleSetModel(rw http.ResponseWriter, req *http.Request) {
+	if w.chatBackend.SetModel == nil {
+		http.NotFound(rw, req)
+		return
+	}
+
+	var body struct {
+		Model string `json:"model"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		http.Error(rw, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	message, err := w.chatBackend.SetModel(req.PathValue("user"), body.Model)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(map[string]string{"message": message}); err != nil {
+		w.l.WithError(err).Error("Failed to encode set-model response")
+	}
 }
 
 // Log logs a generic message
@@ -102,16 +287,27 @@ func (w *WebUI) LogResponse(user, channel, message string) {
 	w.addEntry(entry)
 }
 
-// addEntry adds an entry to the broadcast channel and the entries list
+// LogToolCall logs an agent's invocation of a tool, so operators can watch
+// what the bot did on a user's behalf.
+func (w *WebUI) LogToolCall(user, toolName, result string) {
+	entry := Entry{
+		Type:      "tool-call",
+		Content:   fmt.Sprintf("%s: %s", toolName, result),
+		Timestamp: time.Now(),
+		User:      user,
+	}
+	w.addEntry(entry)
+}
+
+// addEntry broadcasts entry to every connected client's own ring buffer and
+// appends it to the replay buffer sent to new clients.
 func (w *WebUI) addEntry(entry Entry) {
-	// Add to broadcast channel
-	select {
-	case w.broadcastCh <- entry:
-	default:
-		w.l.Warn("Broadcast channel full, dropping message")
+	w.clientsMu.Lock()
+	for _, c := range w.clients {
+		c.send(entry)
 	}
+	w.clientsMu.Unlock()
 
-	// Add to entries list
 	w.entriesMu.Lock()
 	defer w.entriesMu.Unlock()
 
@@ -121,6 +317,14 @@ func (w *WebUI) addEntry(entry Entry) {
 	}
 }
 
+// chatMessage is the shape of an inbound WebSocket message driving the
+// admin panel's chat console: {"type":"chat","userID":"...","text":"..."}.
+type chatMessage struct {
+	Type   string `json:"type"`
+	UserID string `json:"userID"`
+	Text   string `json:"text"`
+}
+
 // handleWebSocket handles WebSocket connections
 func (w *WebUI) handleWebSocket(rw http.ResponseWriter, req *http.Request) {
 	conn, err := w.upgrader.Upgrade(rw, req, nil)
@@ -130,64 +334,135 @@ func (w *WebUI) handleWebSocket(rw http.ResponseWriter, req *http.Request) {
 	}
 	defer conn.Close()
 
-	// Register client
+	client := newWSClient(conn)
 	w.clientsMu.Lock()
-	w.clients[conn] = true
+	w.clients[conn] = client
 	w.clientsMu.Unlock()
 	defer func() {
 		w.clientsMu.Lock()
 		delete(w.clients, conn)
 		w.clientsMu.Unlock()
+		client.close()
 	}()
 
-	// Send recent entries to new client
+	go client.writeLoop(w.l)
+
+	// Send recent entries to new client via its own ring, same as a live broadcast.
 	w.entriesMu.Lock()
 	for _, entry := range w.entries {
-		data, err := json.Marshal(entry)
-		if err != nil {
-			w.l.WithError(err).Error("Failed to marshal log entry")
-			continue
-		}
-		if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
-			w.l.WithError(err).Error("Failed to send log entry to client")
-			break
-		}
+		client.send(entry)
 	}
 	w.entriesMu.Unlock()
 
-	// Keep the connection open by reading messages (we don't expect any, but need to satisfy WebSocket protocol)
 	for {
-		_, _, err := conn.ReadMessage()
+		_, data, err := conn.ReadMessage()
 		if err != nil {
 			if !websocket.IsCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
 				w.l.WithError(err).Error("WebSocket read error")
 			}
 			break
 		}
+		w.handleInbound(data)
 	}
 }
 
-// broadcastMessages broadcasts messages to all connected clients
-func (w *WebUI) broadcastMessages() {
-	for entry := range w.broadcastCh {
+// handleInbound dispatches an inbound WebSocket frame: a {"type":"chat",...}
+// message runs a chat turn through ChatBackend.ChatStream, streaming the
+// reply back to every connected client as "response-chunk" entries. Any
+// other or malformed frame is ignored.
+func (w *WebUI) handleInbound(data []byte) {
+	var msg chatMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		w.l.WithError(err).Warn("Failed to parse WebSocket message")
+		return
+	}
+	if msg.Type != "chat" || w.chatBackend.ChatStream == nil {
+		return
+	}
+
+	w.LogUserInput(msg.UserID, "webui", msg.Text)
+
+	go func() {
+		sw := &responseChunkWriter{webUI: w, userID: msg.UserID}
+		if err := w.chatBackend.ChatStream(msg.UserID, msg.Text, sw); err != nil {
+			w.addEntry(Entry{
+				Type:      "response-chunk",
+				Content:   fmt.Sprintf("Error: %v", err),
+				Timestamp: time.Now(),
+				Channel:   "webui",
+				User:      msg.UserID,
+			})
+		}
+	}()
+}
+
+// responseChunkWriter adapts ChatBackend.ChatStream's io.Writer into a
+// stream of "response-chunk" entries broadcast to every connected client.
+type responseChunkWriter struct {
+	webUI  *WebUI
+	userID string
+}
+
+func (r *responseChunkWriter) Write(p []byte) (int, error) {
+	r.webUI.addEntry(Entry{
+		Type:      "response-chunk",
+		Content:   string(p),
+		Timestamp: time.Now(),
+		Channel:   "webui",
+		User:      r.userID,
+	})
+	return len(p), nil
+}
+
+// wsClient wraps a WebSocket connection with its own outbound ring buffer,
+// so a single slow browser tab can't cause addEntry to drop broadcasts meant
+// for every other connected client.
+type wsClient struct {
+	conn *websocket.Conn
+	ring chan Entry
+}
+
+func newWSClient(conn *websocket.Conn) *wsClient {
+	return &wsClient{conn: conn, ring: make(chan Entry, clientRingSize)}
+}
+
+// send enqueues entry for delivery, dropping the oldest buffered entry (not
+// entry itself) if the client hasn't kept up.
+func (c *wsClient) send(entry Entry) {
+	select {
+	case c.ring <- entry:
+		return
+	default:
+	}
+
+	select {
+	case <-c.ring:
+	default:
+	}
+	select {
+	case c.ring <- entry:
+	default:
+	}
+}
+
+// writeLoop delivers ring's entries to conn until it's closed or a write fails.
+func (c *wsClient) writeLoop(l *logrus.Entry) {
+	for entry := range c.ring {
 		data, err := json.Marshal(entry)
 		if err != nil {
-			w.l.WithError(err).Error("Failed to marshal log entry")
+			l.WithError(err).Error("Failed to marshal log entry")
 			continue
 		}
-
-		w.clientsMu.Lock()
-		for client := range w.clients {
-			if err := client.WriteMessage(websocket.TextMessage, data); err != nil {
-				log.Printf("Failed to send log entry to client: %v", err)
-				client.Close()
-				delete(w.clients, client)
-			}
+		if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+			return
 		}
-		w.clientsMu.Unlock()
 	}
 }
 
+func (c *wsClient) close() {
+	close(c.ring)
+}
+
 // GetWebSocketConnCount returns the number of active WebSocket connections
 func (w *WebUI) GetWebSocketConnCount() int {
 	w.clientsMu.Lock()